@@ -41,20 +41,7 @@ webman add go@18.0.0 zig@9.1.0 rg@13.0.0`,
 			os.Exit(0)
 		}
 		defer os.RemoveAll(utils.WebmanTmpDir)
-		// if local recipe flag is not set
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			shouldRefresh, err := pkgparse.ShouldRefreshRecipes()
-			if err != nil {
-				panic(err)
-			}
-			if shouldRefresh || doRefresh {
-				color.HiBlue("Refreshing package recipes")
-				if err = pkgparse.RefreshRecipes(); err != nil {
-					fmt.Println(err)
-				}
-			}
-		}
+		RefreshRecipesIfStale(doRefresh)
 		if !InstallAllPkgs(args) {
 			color.Magenta("Not all packages installed successfully")
 			os.Exit(1)
@@ -77,6 +64,24 @@ func init() {
 	// addCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// Refreshes the package recipes if they are out of date or force is set,
+// unless local recipes are being used
+func RefreshRecipesIfStale(force bool) {
+	if utils.RecipeDirFlag != "" {
+		return
+	}
+	shouldRefresh, err := pkgparse.ShouldRefreshRecipes()
+	if err != nil {
+		panic(err)
+	}
+	if shouldRefresh || force {
+		color.HiBlue("Refreshing package recipes")
+		if err = pkgparse.RefreshRecipes(); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
 func cleanUpFailedInstall(pkg string, extractPath string) {
 	os.RemoveAll(extractPath)
 	pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
@@ -0,0 +1,131 @@
+package add
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"webman/pkgparse"
+	"webman/unpack"
+	"webman/utils"
+	"webman/verify"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Extra artifacts being downloaded in the background while the main package file downloads
+type artifactDownloads struct {
+	dir       string
+	artifacts []pkgparse.Artifact
+	paths     []string
+	err       error
+	done      chan struct{}
+}
+
+// Starts concurrently downloading and verifying all extra artifacts for a package version
+func startArtifactDownloads(pkgConf *pkgparse.PkgConfig, ver string) *artifactDownloads {
+	d := &artifactDownloads{done: make(chan struct{})}
+	go func() {
+		defer close(d.done)
+		d.err = d.download(pkgConf, ver)
+	}()
+	return d
+}
+
+func (d *artifactDownloads) download(pkgConf *pkgparse.PkgConfig, ver string) error {
+	artifacts, err := pkgConf.GetArtifacts(ver)
+	if err != nil || len(artifacts) == 0 {
+		return err
+	}
+	d.dir, err = os.MkdirTemp(utils.WebmanTmpDir, pkgConf.Title+"-artifacts-*")
+	if err != nil {
+		return err
+	}
+	d.artifacts = artifacts
+	d.paths = make([]string, len(artifacts))
+	var eg errgroup.Group
+	for i, artifact := range artifacts {
+		i := i
+		artifact := artifact
+		eg.Go(func() error {
+			downloadPath := filepath.Join(d.dir, fmt.Sprintf("%d-%s", i, filepath.Base(artifact.Url)))
+			if err := downloadFile(artifact.Url, downloadPath); err != nil {
+				return fmt.Errorf("unable to download %s: %v", artifact.Name, err)
+			}
+			digest, err := verify.FileSha256(downloadPath)
+			if err != nil {
+				return err
+			}
+			for _, sum := range artifact.Checksums {
+				if sum.Sha256 != digest {
+					return fmt.Errorf("checksum mismatch for %s from %s: expected %s, got %s",
+						artifact.Name, sum.Source, sum.Sha256, digest)
+				}
+			}
+			d.paths[i] = downloadPath
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// Waits for the downloads to finish, then places each artifact into the version directory
+func (d *artifactDownloads) install(versionDir string) error {
+	<-d.done
+	if d.err != nil {
+		return d.err
+	}
+	for i, artifact := range d.artifacts {
+		dest := filepath.Join(versionDir, filepath.FromSlash(artifact.Dest))
+		if !strings.HasPrefix(dest+string(os.PathSeparator), filepath.Clean(versionDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid dest %q for %s", artifact.Dest, artifact.Name)
+		}
+		if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+			return err
+		}
+		var err error
+		switch artifact.Ext {
+		case "":
+			err = os.Rename(d.paths[i], filepath.Join(dest, artifact.Name))
+		case "gz":
+			err = unpack.UnGz(d.paths[i], filepath.Join(dest, artifact.Name))
+		default:
+			err = unpack.UnpackTo(d.paths[i], dest, artifact.Ext, unpack.Options{})
+		}
+		if err == nil && artifact.IsBinary && artifact.Ext == "" {
+			err = os.Chmod(filepath.Join(dest, artifact.Name), 0755)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to install %s: %v", artifact.Name, err)
+		}
+	}
+	return nil
+}
+
+// Waits for any in-flight downloads and removes their temporary files
+func (d *artifactDownloads) cleanUp() {
+	<-d.done
+	if d.dir != "" {
+		os.RemoveAll(d.dir)
+	}
+}
+
+func downloadFile(url string, dest string) error {
+	r, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
+		return fmt.Errorf("bad HTTP Response: %s", r.Status)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r.Body)
+	return err
+}
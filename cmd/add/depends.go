@@ -0,0 +1,126 @@
+package add
+
+import (
+	"fmt"
+	"strings"
+	"webman/pkgparse"
+	"webman/utils"
+)
+
+// A package to install, along with the packages that pulled it in as a dependency
+type installItem struct {
+	arg        string
+	pkg        string
+	requested  bool
+	requiredBy []string
+	// dependencies of this package that are being installed in an earlier level
+	deps []string
+	// dependencies whose constraint can only be checked once they are installed
+	checks  []pkgparse.Dependency
+	level   int
+	visited bool
+}
+
+type depResolver struct {
+	items map[string]*installItem
+	order []string
+	path  []string
+}
+
+// Resolves the dependencies of the given package arguments into install levels.
+// Every package only depends on packages from earlier levels,
+// and dependencies that are already installed are left out.
+func resolveInstallOrder(args []string) ([][]*installItem, error) {
+	r := depResolver{items: map[string]*installItem{}}
+	for _, arg := range args {
+		pkg, _, err := utils.ParsePkgVer(arg)
+		if err != nil {
+			// let the install report the malformed argument
+			pkg = arg
+		}
+		if item, exists := r.items[pkg]; exists {
+			item.arg = arg
+			item.requested = true
+			continue
+		}
+		r.items[pkg] = &installItem{arg: arg, pkg: pkg, requested: true}
+		r.order = append(r.order, pkg)
+	}
+	for _, arg := range args {
+		pkg, _, err := utils.ParsePkgVer(arg)
+		if err != nil {
+			continue
+		}
+		if _, err := r.visit(pkg); err != nil {
+			return nil, err
+		}
+	}
+	var levels [][]*installItem
+	for _, pkg := range r.order {
+		item := r.items[pkg]
+		for len(levels) <= item.level {
+			levels = append(levels, nil)
+		}
+		levels[item.level] = append(levels[item.level], item)
+	}
+	return levels, nil
+}
+
+// Visits a package and its dependencies depth-first, returning its install level
+func (r *depResolver) visit(pkg string) (int, error) {
+	for i, visiting := range r.path {
+		if visiting == pkg {
+			cycle := append(append([]string{}, r.path[i:]...), pkg)
+			return 0, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	item := r.items[pkg]
+	if item.visited {
+		return item.level, nil
+	}
+	pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
+	if err != nil {
+		// let the install report the missing or invalid recipe
+		item.visited = true
+		return 0, nil
+	}
+	deps, err := pkgConf.GetDependencies()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", pkg, err)
+	}
+	r.path = append(r.path, pkg)
+	for _, dep := range deps {
+		depItem, exists := r.items[dep.Pkg]
+		if !exists {
+			installed, err := dep.IsInstalled()
+			if err != nil {
+				return 0, err
+			}
+			if installed {
+				continue
+			}
+			depArg := dep.Pkg
+			if dep.Constraint != nil {
+				depArg += "@" + dep.Constraint.String()
+			}
+			depItem = &installItem{arg: depArg, pkg: dep.Pkg}
+			r.items[dep.Pkg] = depItem
+			r.order = append(r.order, dep.Pkg)
+		}
+		depItem.requiredBy = append(depItem.requiredBy, pkg)
+		if dep.Constraint != nil {
+			item.checks = append(item.checks, dep)
+		}
+		depLevel, err := r.visit(dep.Pkg)
+		if err != nil {
+			return 0, err
+		}
+		item.deps = append(item.deps, dep.Pkg)
+		if depLevel+1 > item.level {
+			item.level = depLevel + 1
+		}
+	}
+	r.path = r.path[:len(r.path)-1]
+	item.visited = true
+	return item.level, nil
+}
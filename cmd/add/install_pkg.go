@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"webman/link"
 	"webman/multiline"
@@ -15,24 +17,78 @@ import (
 )
 
 func InstallAllPkgs(args []string) bool {
+	failed, err := InstallPkgsReportFailed(args)
+	if err != nil {
+		color.Red("%v", err)
+		return false
+	}
+	return len(failed) == 0
+}
+
+// Installs the packages along with their dependencies,
+// returning the names of the packages that failed to install
+func InstallPkgsReportFailed(args []string) (map[string]bool, error) {
+	levels, err := resolveInstallOrder(args)
+	if err != nil {
+		return nil, err
+	}
+	failed := map[string]bool{}
+	for _, level := range levels {
+		var levelItems []*installItem
+	itemLoop:
+		for _, item := range level {
+			for _, dep := range item.deps {
+				if failed[dep] {
+					color.Red("Skipping %s because its dependency %s failed to install", item.pkg, dep)
+					failed[item.pkg] = true
+					continue itemLoop
+				}
+			}
+			for _, check := range item.checks {
+				if installed, err := check.IsInstalled(); err != nil || !installed {
+					color.Red("Skipping %s because it requires %s, which is not installed", item.pkg, check.String())
+					failed[item.pkg] = true
+					continue itemLoop
+				}
+			}
+			if !item.requested {
+				requiredBy := append([]string{}, item.requiredBy...)
+				sort.Strings(requiredBy)
+				color.HiBlack("Adding dependency %s, required by %s", item.pkg, strings.Join(requiredBy, ", "))
+			}
+			levelItems = append(levelItems, item)
+		}
+		levelArgs := make([]string, len(levelItems))
+		for i, item := range levelItems {
+			levelArgs[i] = item.arg
+		}
+		for i, res := range installBatch(levelArgs) {
+			if !res {
+				failed[levelItems[i].pkg] = true
+			}
+		}
+	}
+	return failed, nil
+}
+
+// Installs the given packages concurrently, returning whether each one succeeded
+func installBatch(args []string) []bool {
+	if len(args) == 0 {
+		return nil
+	}
 	var wg sync.WaitGroup
 	ml := multiline.New(len(args), os.Stdout)
 	wg.Add(len(args))
-	results := make(chan bool, len(args))
+	results := make([]bool, len(args))
 	for i, arg := range args {
 		i := i
 		arg := arg
 		go func() {
-			res := InstallPkg(arg, i, len(args), &wg, &ml)
-			results <- res
+			results[i] = InstallPkg(arg, i, len(args), &wg, &ml)
 		}()
 	}
 	wg.Wait()
-	success := true
-	for i := 0; i < len(args); i++ {
-		success = success && <-results
-	}
-	return success
+	return results
 }
 
 func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *multiline.MultiLogger) bool {
@@ -66,7 +122,12 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 			return false
 		}
 	}
-	if len(ver) == 0 || pkgConf.ForceLatest {
+	spec, err := pkgConf.ParseVersionSpec(ver)
+	if err != nil {
+		ml.Printf(argIndex, color.RedString("%v", err))
+		return false
+	}
+	if spec.IsLatest() || pkgConf.ForceLatest {
 		foundLatest := make(chan bool)
 		ml.PrintUntilDone(argIndex,
 			fmt.Sprintf("Finding latest %s version tag", color.CyanString(pkg)),
@@ -79,13 +140,37 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 			ml.Printf(argIndex, color.RedString("unable to find latest version tag: %v", err))
 			return false
 		}
-		if pkgConf.ForceLatest && len(ver) != 0 && *verPtr != ver {
-			ml.Printf(argIndex, color.RedString("This package requires using the latest version, which is currently %s",
-				color.MagentaString(*verPtr)))
-			return false
+		if pkgConf.ForceLatest && !spec.IsLatest() {
+			matches, err := pkgConf.CheckVersion(spec, *verPtr)
+			if err != nil {
+				ml.Printf(argIndex, color.RedString("%v", err))
+				return false
+			}
+			if !matches {
+				ml.Printf(argIndex, color.RedString("This package requires using the latest version, which is currently %s",
+					color.MagentaString(*verPtr)))
+				return false
+			}
 		}
 		ver = *verPtr
 		ml.Printf(argIndex, "Found %s version tag: %s", color.CyanString(pkg), color.MagentaString(ver))
+	} else if exact, isExact := spec.Exact(); isExact {
+		ver = exact
+	} else {
+		foundMatch := make(chan bool)
+		ml.PrintUntilDone(argIndex,
+			fmt.Sprintf("Finding %s version matching %s", color.CyanString(pkg), color.MagentaString(ver)),
+			foundMatch,
+			500,
+		)
+		verPtr, err := pkgConf.ResolveSpec(spec)
+		foundMatch <- true
+		if err != nil {
+			ml.Printf(argIndex, color.RedString("%v", err))
+			return false
+		}
+		ver = *verPtr
+		ml.Printf(argIndex, "Found %s version: %s", color.CyanString(pkg), color.MagentaString(ver))
 	}
 	stemPtr, extPtr, urlPtr, err := pkgConf.GetAssetStemExtUrl(ver)
 	if err != nil {
@@ -110,6 +195,8 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 		ml.Printf(argIndex, color.HiBlackString("Already installed!"))
 		return true
 	}
+	artifacts := startArtifactDownloads(pkgConf, ver)
+	defer artifacts.cleanUp()
 	f, err := os.OpenFile(downloadPath,
 		os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -117,12 +204,24 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 		return false
 	}
 	defer f.Close()
-	if pkgConf.IsBinary && utils.GOOS == "windows" {
+	if pkgConf.IsBinary && utils.GOOS == "windows" && !pkgConf.UsesAssetPattern() {
 		url += ".exe"
 	}
 	if !DownloadUrl(url, f, pkg, ver, argIndex, argCount, ml) {
 		return false
 	}
+	if err = verifyDownload(pkgConf, ver, url, downloadPath, argIndex, ml); err != nil {
+		ml.Printf(argIndex, color.RedString("%v", err))
+		os.Remove(downloadPath)
+		cleanUpFailedInstall(pkg, extractPath)
+		return false
+	}
+	if err = verifySignature(pkgConf, ver, downloadPath, argIndex, ml); err != nil {
+		ml.Printf(argIndex, color.RedString("%v", err))
+		os.Remove(downloadPath)
+		cleanUpFailedInstall(pkg, extractPath)
+		return false
+	}
 	if pkgConf.IsBinary {
 		if err = os.Chmod(downloadPath, 0755); err != nil {
 			ml.Printf(argIndex, color.RedString("Failed to make download executable!"))
@@ -138,13 +237,19 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 			return false
 		}
 	} else {
+		unpackOpts, err := pkgConf.GetUnpackOptions(ver)
+		if err != nil {
+			ml.Printf(argIndex, color.RedString("%v", err))
+			cleanUpFailedInstall(pkg, extractPath)
+			return false
+		}
 		hasUnpacked := make(chan bool)
 		ml.PrintUntilDone(argIndex,
 			fmt.Sprintf("Unpacking %s.%s", stem, ext),
 			hasUnpacked,
 			500,
 		)
-		err = unpack.Unpack(downloadPath, pkg, extractStem, ext, pkgConf.ExtractHasRoot)
+		err = unpack.Unpack(downloadPath, pkg, extractStem, ext, *unpackOpts)
 		hasUnpacked <- true
 		if err != nil {
 			ml.Printf(argIndex, color.RedString("%v", err))
@@ -153,6 +258,21 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 		}
 		ml.Printf(argIndex, "Completed unpacking %s@%s", color.CyanString(pkg), color.MagentaString(ver))
 	}
+	if len(pkgConf.ExtraArtifacts) != 0 {
+		hasInstalled := make(chan bool)
+		ml.PrintUntilDone(argIndex,
+			fmt.Sprintf("Installing %d extra artifacts", len(pkgConf.ExtraArtifacts)),
+			hasInstalled,
+			500,
+		)
+		err = artifacts.install(extractPath)
+		hasInstalled <- true
+		if err != nil {
+			ml.Printf(argIndex, color.RedString("%v", err))
+			cleanUpFailedInstall(pkg, extractPath)
+			return false
+		}
+	}
 	using, err := pkgparse.CheckUsing(pkg)
 	if err != nil {
 		cleanUpFailedInstall(pkg, extractPath)
@@ -165,13 +285,21 @@ func InstallPkg(arg string, argIndex int, argCount int, wg *sync.WaitGroup, ml *
 			ml.Printf(argIndex, color.RedString("%v", err))
 			return false
 		}
+		if err = link.ReplaceShareLinks(pkg, using, ver, pkgConf); err != nil {
+			link.RestoreLinks(pkg, using, ver, binPaths, pkgConf)
+			cleanUpFailedInstall(pkg, extractPath)
+			ml.Printf(argIndex, color.RedString("Failed creating completion and man page links: %v", err))
+			return false
+		}
 		madeLinks, err := link.CreateLinks(pkg, ver, binPaths)
 		if err != nil {
+			link.RestoreLinks(pkg, using, ver, binPaths, pkgConf)
 			cleanUpFailedInstall(pkg, extractPath)
 			ml.Printf(argIndex, color.RedString("Failed creating links: %v", err))
 			return false
 		}
 		if !madeLinks {
+			link.RestoreLinks(pkg, using, ver, binPaths, pkgConf)
 			cleanUpFailedInstall(pkg, extractPath)
 			ml.Printf(argIndex, color.RedString("Failed creating links"))
 			return false
@@ -0,0 +1,74 @@
+package add
+
+import (
+	"fmt"
+	"webman/multiline"
+	"webman/pkgparse"
+	"webman/verify"
+
+	"github.com/fatih/color"
+)
+
+// Checks the downloaded file against every checksum the recipe declares.
+// Packages with no checksum sources are not verified.
+func verifyDownload(pkgConf *pkgparse.PkgConfig, ver string, url string, downloadPath string, argIndex int, ml *multiline.MultiLogger) error {
+	if !pkgConf.HasChecksum() {
+		return nil
+	}
+	hasVerified := make(chan bool)
+	ml.PrintUntilDone(argIndex,
+		fmt.Sprintf("Verifying checksum for %s", color.CyanString(pkgConf.Title)),
+		hasVerified,
+		500,
+	)
+	sums, err := pkgConf.GetChecksums(ver, url)
+	if err != nil {
+		hasVerified <- true
+		return fmt.Errorf("unable to resolve checksum: %v", err)
+	}
+	digest, err := verify.FileSha256(downloadPath)
+	hasVerified <- true
+	if err != nil {
+		return fmt.Errorf("unable to compute checksum: %v", err)
+	}
+	for _, sum := range sums {
+		if sum.Sha256 != digest {
+			return fmt.Errorf("checksum mismatch from %s: expected %s, got %s", sum.Source, sum.Sha256, digest)
+		}
+	}
+	ml.Printf(argIndex, "Verified checksum for %s@%s", color.CyanString(pkgConf.Title), color.MagentaString(ver))
+	return nil
+}
+
+// Checks the downloaded file against the detached signature declared by the recipe.
+// Packages with no signature_url are not verified.
+func verifySignature(pkgConf *pkgparse.PkgConfig, ver string, downloadPath string, argIndex int, ml *multiline.MultiLogger) error {
+	if !pkgConf.HasSignature() {
+		return nil
+	}
+	hasVerified := make(chan bool)
+	ml.PrintUntilDone(argIndex,
+		fmt.Sprintf("Verifying signature for %s", color.CyanString(pkgConf.Title)),
+		hasVerified,
+		500,
+	)
+	sig, err := pkgConf.GetSignature(ver)
+	if err != nil {
+		hasVerified <- true
+		return err
+	}
+	switch pkgConf.GetSignatureType() {
+	case pkgparse.SignatureMinisign:
+		err = verify.Minisign(downloadPath, sig, pkgConf.PublicKey)
+	case pkgparse.SignatureSsh:
+		err = verify.SshSignature(downloadPath, sig, pkgConf.PublicKey, pkgConf.SignatureNamespace)
+	default:
+		err = fmt.Errorf("unsupported signature type %q", pkgConf.SignatureType)
+	}
+	hasVerified <- true
+	if err != nil {
+		return err
+	}
+	ml.Printf(argIndex, "Verified signature for %s@%s", color.CyanString(pkgConf.Title), color.MagentaString(ver))
+	return nil
+}
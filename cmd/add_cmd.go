@@ -2,22 +2,32 @@ package cmd
 
 import (
 	"webman/cmd/add"
+	"webman/cmd/alias"
 	"webman/cmd/dev"
+	"webman/cmd/env"
 	"webman/cmd/group"
+	"webman/cmd/outdated"
 	"webman/cmd/remove"
 	"webman/cmd/run"
 	"webman/cmd/search"
 	switchcmd "webman/cmd/switch"
+	"webman/cmd/upgrade"
 	"webman/cmd/version"
+	"webman/cmd/versions"
 )
 
 func init() {
 	rootCmd.AddCommand(add.AddCmd)
+	rootCmd.AddCommand(alias.AliasCmd)
 	rootCmd.AddCommand(dev.DevCmd)
+	rootCmd.AddCommand(env.EnvCmd)
 	rootCmd.AddCommand(remove.RemoveCmd)
 	rootCmd.AddCommand(run.RunCmd)
 	rootCmd.AddCommand(switchcmd.SwitchCmd)
+	rootCmd.AddCommand(upgrade.UpgradeCmd)
 	rootCmd.AddCommand(group.GroupCmd)
+	rootCmd.AddCommand(outdated.OutdatedCmd)
 	rootCmd.AddCommand(search.SearchCmd)
 	rootCmd.AddCommand(version.VersionCmd)
+	rootCmd.AddCommand(versions.VersionsCmd)
 }
@@ -0,0 +1,122 @@
+package alias
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"webman/pkgparse"
+	"webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var deleteFlag bool
+
+var AliasCmd = &cobra.Command{
+	Use:   "alias [pkg] [name] [version]",
+	Short: "name versions of a package",
+	Long: `
+The "alias" subcommand saves a name for a version of a package in ~/.webman/aliases.yaml,
+which can be used anywhere a version goes, like "webman add go@work" or "webman run go@work".
+The version can be an exact version, a version constraint, or a channel.
+With only a package, it lists the package's aliases and channels.`,
+	Example: `webman alias go
+webman alias go work 1.18.3
+webman alias node current ^18
+webman alias go work --delete`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Init()
+		if len(args) == 0 || len(args) > 3 || (deleteFlag && len(args) != 2) ||
+			(!deleteFlag && len(args) == 2) {
+			cmd.Help()
+			os.Exit(0)
+		}
+		pkg := args[0]
+		pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		switch {
+		case deleteFlag:
+			if err = pkgConf.RemoveAlias(args[1]); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			color.Green("Removed alias %s for %s", color.CyanString(args[1]), color.CyanString(pkg))
+		case len(args) == 3:
+			if err = pkgConf.SetAlias(args[1], args[2]); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			color.Green("%s@%s now means %s", pkg, color.CyanString(args[1]), color.MagentaString(args[2]))
+		default:
+			printAliases(pkgConf)
+		}
+	},
+}
+
+func printAliases(pkgConf *pkgparse.PkgConfig) {
+	aliases, err := pkgConf.GetAliases()
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+	if len(aliases) == 0 {
+		color.HiBlack("No aliases for %s.", pkgConf.Title)
+	} else {
+		fmt.Println("Aliases:")
+		for _, name := range sortedKeys(aliases) {
+			fmt.Printf("  %s -> %s\n", color.CyanString(name), color.MagentaString(aliases[name]))
+		}
+	}
+	channels := append([]string{}, pkgparse.BuiltinChannels...)
+	for name := range pkgConf.Channels {
+		channels = append(channels, name)
+	}
+	fmt.Println("Channels:")
+	for _, name := range uniqueSorted(channels) {
+		ch, _ := pkgConf.GetChannel(name)
+		var filters []string
+		if ch.Constraint != "" {
+			filters = append(filters, ch.Constraint)
+		}
+		if ch.Lts {
+			filters = append(filters, "lts")
+		}
+		if ch.AllowPrerelease {
+			filters = append(filters, "prereleases")
+		}
+		line := "  " + color.CyanString(name)
+		if len(filters) != 0 {
+			line += color.HiBlackString(" (" + strings.Join(filters, ", ") + ")")
+		}
+		fmt.Println(line)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func uniqueSorted(strs []string) []string {
+	sort.Strings(strs)
+	var unique []string
+	for i, str := range strs {
+		if i == 0 || str != strs[i-1] {
+			unique = append(unique, str)
+		}
+	}
+	return unique
+}
+
+func init() {
+	AliasCmd.Flags().BoolVarP(&deleteFlag, "delete", "d", false, "delete the alias")
+}
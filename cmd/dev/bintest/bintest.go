@@ -75,6 +75,9 @@ The "bintest" tests that binary paths given in a package recipe have valid binar
 					}
 				}
 				fmt.Printf("Trying %s-%s installation\n", osStr, arch)
+				if _, hasOverride := pkgConf.Platforms[osPkgStr+"-"+arch]; hasOverride {
+					color.HiBlack("Using platforms override for %s-%s", osPkgStr, arch)
+				}
 				InitTestDir(osStr, arch, homedir, testDir)
 				var wg sync.WaitGroup
 				ml := multiline.New(len(args), os.Stdout)
@@ -97,6 +100,23 @@ The "bintest" tests that binary paths given in a package recipe have valid binar
 				for i := range binPaths {
 					color.Magenta("   %s", binPaths[i])
 				}
+				sharePaths, _, err := link.GetSharePathsAndLinkPaths(pkg, *latestVer, pkgConf)
+				if err != nil {
+					color.Red("Error getting completion and man page paths: %v", err)
+					pairResults[osPairStr] = false
+					continue
+				}
+				if len(sharePaths) != 0 {
+					fmt.Println("  Installation Completion & Man Page Paths:")
+					for _, sharePath := range sharePaths {
+						if _, err := os.Stat(sharePath); err != nil {
+							color.Red("   %s (missing)", sharePath)
+							pairResults[osPairStr] = false
+						} else {
+							color.Magenta("   %s", sharePath)
+						}
+					}
+				}
 			}
 		}
 		allSucceed := true
@@ -125,6 +145,7 @@ func InitTestDir(osStr string, arch string, homedir string, testdir string) {
 	utils.WebmanDir = filepath.Join(testdir, osStr, arch)
 	utils.WebmanPkgDir = filepath.Join(utils.WebmanDir, "/pkg")
 	utils.WebmanBinDir = filepath.Join(utils.WebmanDir, "/bin")
+	utils.WebmanShareDir = filepath.Join(utils.WebmanDir, "/share")
 	utils.WebmanTmpDir = filepath.Join(utils.WebmanDir, "/tmp")
 	// leave WebmanRecipesDir the way it was
 
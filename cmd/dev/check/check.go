@@ -2,10 +2,14 @@ package check
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"webman/jsonpath"
 	"webman/pkgparse"
 	"webman/utils"
 
@@ -76,11 +80,26 @@ func CheckPkgConfig(pkg string) error {
 		return fmt.Errorf("about field empty")
 	}
 
-	if len(pkgConf.FilenameFormat) == 0 {
-		return fmt.Errorf("filename_format field empty")
-	}
-	if len(pkgConf.BaseDownloadUrl) == 0 {
-		return fmt.Errorf("base_download_url field empty")
+	if pkgConf.UsesAssetPattern() {
+		if len(pkgConf.AssetPattern) != 0 && len(pkgConf.AssetRegex) != 0 {
+			return fmt.Errorf("only one of asset_pattern and asset_regex may be set")
+		}
+		if len(pkgConf.GitUser) == 0 || len(pkgConf.GitRepo) == 0 {
+			return fmt.Errorf("missing git_user or git_repo because asset pattern is set")
+		}
+		if _, err := path.Match(pkgConf.AssetPattern, ""); err != nil {
+			return fmt.Errorf("invalid asset_pattern: %v", err)
+		}
+		if _, err := regexp.Compile(pkgConf.AssetRegex); err != nil {
+			return fmt.Errorf("invalid asset_regex: %v", err)
+		}
+	} else if !pkgConf.UsesVendorDownloads() {
+		if len(pkgConf.FilenameFormat) == 0 {
+			return fmt.Errorf("filename_format field empty")
+		}
+		if len(pkgConf.BaseDownloadUrl) == 0 {
+			return fmt.Errorf("base_download_url field empty")
+		}
 	}
 	if len(pkgConf.LatestStrategy) == 0 {
 		return fmt.Errorf("latest_strategy field empty")
@@ -93,13 +112,274 @@ func CheckPkgConfig(pkg string) error {
 		if len(pkgConf.GitRepo) == 0 {
 			return fmt.Errorf("missing git_repo because github-release latest strategy")
 		}
-	case "arch-linux-community":
+	case "github-latest-redirect":
+		if len(pkgConf.GitUser) == 0 {
+			return fmt.Errorf("missing git_user because github-latest-redirect latest strategy")
+		}
+		if len(pkgConf.GitRepo) == 0 {
+			return fmt.Errorf("missing git_repo because github-latest-redirect latest strategy")
+		}
+		if pkgConf.AllowPrerelease {
+			return fmt.Errorf("allow_prerelease is not supported by the github-latest-redirect latest strategy")
+		}
+	case "github-tags":
+		if len(pkgConf.GitUser) == 0 {
+			return fmt.Errorf("missing git_user because github-tags latest strategy")
+		}
+		if len(pkgConf.GitRepo) == 0 {
+			return fmt.Errorf("missing git_repo because github-tags latest strategy")
+		}
+		if err := checkVersionFormat(pkgConf.VersionFormat); err != nil {
+			return err
+		}
+	case "gitlab-release":
+		if len(pkgConf.GitlabProject) == 0 && (len(pkgConf.GitUser) == 0 || len(pkgConf.GitRepo) == 0) {
+			return fmt.Errorf("missing gitlab_project or git_user and git_repo because gitlab-release latest strategy")
+		}
+		if len(pkgConf.GitlabHost) != 0 {
+			if u, err := url.Parse(pkgConf.GitlabHost); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("gitlab_host must be a URL like https://gitlab.example.com")
+			}
+		}
+	case "gitea-release":
+		if len(pkgConf.GitUser) == 0 {
+			return fmt.Errorf("missing git_user because gitea-release latest strategy")
+		}
+		if len(pkgConf.GitRepo) == 0 {
+			return fmt.Errorf("missing git_repo because gitea-release latest strategy")
+		}
+		if len(pkgConf.GiteaHost) != 0 {
+			if u, err := url.Parse(pkgConf.GiteaHost); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("gitea_host must be a URL like https://gitea.example.com")
+			}
+		}
+	case "html-regex":
+		if len(pkgConf.VersionsUrl) == 0 {
+			return fmt.Errorf("missing versions_url because html-regex latest strategy")
+		}
+		if len(pkgConf.VersionsRegex) == 0 {
+			return fmt.Errorf("missing versions_regex because html-regex latest strategy")
+		}
+		if err := pkgparse.CheckVersionsRegex(pkgConf.VersionsRegex); err != nil {
+			return err
+		}
+		if err := checkVersionFormat(pkgConf.VersionFormat); err != nil {
+			return err
+		}
+	case "json-api":
+		if len(pkgConf.VersionsUrl) == 0 {
+			return fmt.Errorf("missing versions_url because json-api latest strategy")
+		}
+		if len(pkgConf.VersionsSelector) == 0 {
+			return fmt.Errorf("missing versions_selector because json-api latest strategy")
+		}
+		if _, err := jsonpath.Compile(pkgConf.VersionsSelector); err != nil {
+			return err
+		}
+		if err := checkVersionFormat(pkgConf.VersionFormat); err != nil {
+			return err
+		}
+	case "go-index", "node-index", "zig-index", "hashicorp-index":
+		if pkgConf.LtsOnly && pkgConf.LatestStrategy != "node-index" {
+			return fmt.Errorf("lts_only is only supported by the node-index latest strategy")
+		}
+		if len(pkgConf.HashicorpProduct) != 0 && pkgConf.LatestStrategy != "hashicorp-index" {
+			return fmt.Errorf("hashicorp_product is only used by the hashicorp-index latest strategy")
+		}
+	case "static":
+		if len(pkgConf.Versions) == 0 {
+			return fmt.Errorf("missing versions because static latest strategy")
+		}
+		if err := checkVersionFormat(pkgConf.VersionFormat); err != nil {
+			return err
+		}
+		for _, ver := range pkgConf.Versions {
+			if _, err := pkgparse.ParseVersion(ver, pkgConf.VersionFormat); err != nil {
+				return fmt.Errorf("versions: %v", err)
+			}
+		}
+	case "file":
+		if len(pkgConf.VersionsFile) == 0 {
+			return fmt.Errorf("missing versions_file because file latest strategy")
+		}
+		versionsPath, err := pkgConf.GetVersionsFilePath()
+		if err != nil {
+			return err
+		}
+		// manifests shipped next to the recipe should exist, absolute ones may be machine-specific
+		if !filepath.IsAbs(pkgConf.VersionsFile) && !strings.HasPrefix(pkgConf.VersionsFile, "file://") {
+			if _, err := os.Stat(versionsPath); err != nil {
+				return fmt.Errorf("versions_file: %v", err)
+			}
+		}
+		if err := checkVersionFormat(pkgConf.VersionFormat); err != nil {
+			return err
+		}
+	case "arch-linux", "arch-linux-community":
 		if len(pkgConf.ArchLinuxPkgName) == 0 {
-			return fmt.Errorf("missing arch_linux_pkg_name because arch-linux-community latest strategy")
+			return fmt.Errorf("missing arch_linux_pkg_name because %s latest strategy", pkgConf.LatestStrategy)
+		}
+		switch pkgConf.ArchLinuxRepo {
+		case "", "core", "extra", "community":
+		default:
+			return fmt.Errorf("invalid arch_linux_repo, expected core, extra or community")
 		}
 	default:
 		return fmt.Errorf("invalid latest strategy")
 	}
+	if err := pkgConf.CheckChannels(); err != nil {
+		return err
+	}
+	deps, err := pkgConf.GetDependencies()
+	if err != nil {
+		return fmt.Errorf("depends: %v", err)
+	}
+	for _, dep := range deps {
+		if dep.Pkg == pkg {
+			return fmt.Errorf("depends: package can't depend on itself")
+		}
+		if _, err := pkgparse.ParsePkgConfigLocal(dep.Pkg, false); err != nil {
+			return fmt.Errorf("depends: %v", err)
+		}
+	}
+	if pkgConf.StripComponents < 0 {
+		return fmt.Errorf("strip_components must not be negative")
+	}
+	if err := checkSubdir(pkgConf.ExtractSubdir); err != nil {
+		return err
+	}
+	for platform, override := range pkgConf.Platforms {
+		if override.StripComponents != nil && *override.StripComponents < 0 {
+			return fmt.Errorf("platforms: strip_components for %s must not be negative", platform)
+		}
+		if override.ExtractSubdir != nil {
+			if err := checkSubdir(*override.ExtractSubdir); err != nil {
+				return fmt.Errorf("platforms: %v for %s", err, platform)
+			}
+		}
+	}
+	for i, artifact := range pkgConf.ExtraArtifacts {
+		if len(artifact.Url) == 0 {
+			return fmt.Errorf("extra_artifacts[%d]: url field empty", i)
+		}
+		if err := checkSubdir(artifact.Dest); err != nil {
+			return fmt.Errorf("extra_artifacts[%d]: dest must be a relative path inside the package", i)
+		}
+		if len(artifact.Sha256) != 0 && !pkgparse.IsSha256(artifact.Sha256) {
+			return fmt.Errorf("extra_artifacts[%d]: sha256 is not a SHA-256 digest", i)
+		}
+	}
+	for _, completion := range []string{pkgConf.Completions.Bash, pkgConf.Completions.Zsh, pkgConf.Completions.Fish} {
+		if err := checkSubdir(completion); err != nil {
+			return fmt.Errorf("completions: %q must be a relative path inside the package", completion)
+		}
+	}
+	for _, manPage := range pkgConf.ManPages.Values {
+		if err := checkSubdir(manPage); err != nil {
+			return fmt.Errorf("man_pages: %q must be a relative path inside the package", manPage)
+		}
+	}
+	if err := pkgConf.CheckTemplates(); err != nil {
+		return err
+	}
+	if err := pkgConf.CheckEnv(); err != nil {
+		return err
+	}
+	for platform := range pkgConf.Platforms {
+		if err := checkPlatformKey(pkgConf, platform); err != nil {
+			return fmt.Errorf("platforms: %v", err)
+		}
+	}
+	if err := checkInlineSha256(pkgConf); err != nil {
+		return err
+	}
+	if pkgConf.GithubDigest && (len(pkgConf.GitUser) == 0 || len(pkgConf.GitRepo) == 0) {
+		return fmt.Errorf("missing git_user or git_repo because github_digest is set")
+	}
+	if pkgConf.HasSignature() {
+		if len(pkgConf.PublicKey) == 0 {
+			return fmt.Errorf("missing public_key because signature_url is set")
+		}
+		switch pkgConf.GetSignatureType() {
+		case pkgparse.SignatureMinisign, pkgparse.SignatureSsh:
+		default:
+			return fmt.Errorf("invalid signature_type, expected minisign or ssh")
+		}
+	}
+	return nil
+}
+
+// Makes sure version_format (if set) can match tags and capture the version
+func checkVersionFormat(versionFmt string) error {
+	if versionFmt == "" {
+		return nil
+	}
+	if !strings.Contains(versionFmt, "[VER]") {
+		return fmt.Errorf("version_format must contain [VER]")
+	}
+	if _, err := regexp.Compile(strings.Replace(versionFmt, "[VER]", "(.+)", 1)); err != nil {
+		return fmt.Errorf("invalid version_format: %v", err)
+	}
+	return nil
+}
+
+// Checks that an extract_subdir stays inside the archive
+func checkSubdir(subdir string) error {
+	if subdir == "" {
+		return nil
+	}
+	cleaned := path.Clean(subdir)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("extract_subdir %q must be a relative path inside the archive", subdir)
+	}
+	return nil
+}
+
+// Checks that a per-platform key names an os_map OS and an arch_map arch
+func checkPlatformKey(pkgConf *pkgparse.PkgConfig, platform string) error {
+	osStr, arch, found := strings.Cut(platform, "-")
+	if _, osSupported := pkgConf.OsMap[osStr]; !found || !osSupported {
+		return fmt.Errorf("key %q is not an os_map OS and arch, like linux-amd64", platform)
+	}
+	if _, archSupported := pkgConf.ArchMap[arch]; !archSupported {
+		return fmt.Errorf("key %q has an arch missing from arch_map", platform)
+	}
+	return nil
+}
+
+// Checks that inline sha256 digests cover every listed version of a static recipe.
+// Other strategies find new versions the recipe has no digests for, so they can't use sha256.
+func checkInlineSha256(pkgConf *pkgparse.PkgConfig) error {
+	if len(pkgConf.Sha256) == 0 {
+		return nil
+	}
+	if pkgConf.LatestStrategy != "static" {
+		return fmt.Errorf("sha256 is only supported by the static latest strategy, use checksum_url or github_digest instead")
+	}
+	listed := map[string]bool{}
+	for _, tag := range pkgConf.Versions {
+		ver := tag
+		if parsed, err := pkgparse.ParseVersion(tag, pkgConf.VersionFormat); err == nil {
+			ver = *parsed
+		}
+		listed[ver] = true
+		if _, exists := pkgConf.Sha256[ver]; !exists {
+			return fmt.Errorf("sha256: missing digests for version %s", ver)
+		}
+	}
+	for ver, sums := range pkgConf.Sha256 {
+		if !listed[ver] {
+			return fmt.Errorf("sha256: version %s is not in versions", ver)
+		}
+		for platform, sum := range sums {
+			if err := checkPlatformKey(pkgConf, platform); err != nil {
+				return fmt.Errorf("sha256: %v", err)
+			}
+			if !pkgparse.IsSha256(sum) {
+				return fmt.Errorf("sha256 for %s on %s is not a SHA-256 digest", ver, platform)
+			}
+		}
+	}
 	return nil
 }
 
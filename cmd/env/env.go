@@ -0,0 +1,112 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"webman/pkgparse"
+	"webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var shellFlag string
+
+var EnvCmd = &cobra.Command{
+	Use:   "env [pkgs...]",
+	Short: "print environment variables for in-use packages",
+	Long: `
+The "env" subcommand prints shell commands that export the environment variables
+declared by the in-use versions of installed packages, or only the given packages.
+Evaluate the output in your shell profile to follow "webman switch".`,
+	Example: `eval "$(webman env)"
+webman env go java
+webman env --shell fish | source
+webman env --shell powershell | Invoke-Expression`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Init()
+		// errors go to stderr so they aren't evaluated along with the output
+		if !isKnownShell(shellFlag) {
+			fmt.Fprintln(os.Stderr, color.RedString("Unknown shell %q, expected sh, bash, zsh, fish or powershell", shellFlag))
+			os.Exit(1)
+		}
+		pkgs := args
+		if len(pkgs) == 0 {
+			entries, err := os.ReadDir(utils.WebmanPkgDir)
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Fprintln(os.Stderr, color.RedString("Unable to read package directory: %v", err))
+				os.Exit(1)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					pkgs = append(pkgs, entry.Name())
+				}
+			}
+		}
+		success := true
+		for _, pkg := range pkgs {
+			using, err := pkgparse.CheckUsing(pkg)
+			if err != nil || using == nil {
+				continue
+			}
+			pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString("%v", err))
+				success = false
+				continue
+			}
+			_, ver := utils.ParseStem(*using)
+			env, err := pkgConf.GetEnv(ver)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString("%s: %v", pkg, err))
+				success = false
+				continue
+			}
+			for _, pair := range env {
+				key, val, _ := strings.Cut(pair, "=")
+				fmt.Println(exportLine(shellFlag, key, val))
+			}
+		}
+		if !success {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	defaultShell := "sh"
+	// utils.GOOS is only set once a command runs
+	if runtime.GOOS == "windows" {
+		defaultShell = "powershell"
+	}
+	EnvCmd.Flags().StringVarP(&shellFlag, "shell", "s", defaultShell, "shell syntax to print (sh, fish, powershell)")
+}
+
+// Returns a shell command setting an environment variable.
+// The key is a valid name, so only the value needs quoting.
+func exportLine(shell string, key string, val string) string {
+	switch shell {
+	case "fish":
+		// only \\ and \' are escapes inside fish single quotes
+		val = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(val)
+		return fmt.Sprintf("set -gx %s '%s';", key, val)
+	case "powershell", "pwsh":
+		// powershell also ends single-quoted strings at typographic quotes, so each is doubled
+		val = strings.NewReplacer("'", "''", "\u2018", "\u2018\u2018", "\u2019", "\u2019\u2019",
+			"\u201a", "\u201a\u201a", "\u201b", "\u201b\u201b").Replace(val)
+		return fmt.Sprintf("$env:%s = '%s'", key, val)
+	default:
+		return fmt.Sprintf("export %s='%s'", key, strings.ReplaceAll(val, "'", `'\''`))
+	}
+}
+
+// Shells that exportLine can print syntax for
+func isKnownShell(shell string) bool {
+	switch shell {
+	case "sh", "bash", "zsh", "fish", "powershell", "pwsh":
+		return true
+	}
+	return false
+}
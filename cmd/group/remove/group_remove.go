@@ -50,6 +50,47 @@ The "group remove" subcommand removes a group of packages.
 			color.HiBlack("No packages selected for removal.")
 			os.Exit(0)
 		}
+		// group files may give a version, like "node@lts", but every version is removed
+		for i, entry := range pkgsToRemove {
+			pkg, _, err := utils.ParsePkgVer(entry)
+			if err != nil {
+				color.Red(err.Error())
+				os.Exit(1)
+			}
+			pkgsToRemove[i] = pkg
+		}
+		breaks := false
+		for _, pkg := range pkgsToRemove {
+			vers, err := pkgparse.InstalledVersions(pkg)
+			if err != nil {
+				color.Red(err.Error())
+				os.Exit(1)
+			}
+			pkgVerStems := make([]string, len(vers))
+			for i, ver := range vers {
+				pkgVerStems[i] = utils.CreateStem(pkg, ver)
+			}
+			pkgBreaks, err := remove.WarnDependents(pkg, pkgVerStems, pkgsToRemove)
+			if err != nil {
+				color.Red(err.Error())
+				os.Exit(1)
+			}
+			breaks = breaks || pkgBreaks
+		}
+		if breaks && !allFlag {
+			proceed := false
+			prompt := &survey.Confirm{
+				Message: "Remove anyway?",
+			}
+			if err := survey.AskOne(prompt, &proceed); err != nil {
+				fmt.Printf("Prompt failed %v\n", err)
+				return
+			}
+			if !proceed {
+				color.HiBlack("No packages removed.")
+				os.Exit(0)
+			}
+		}
 		for _, pkg := range pkgsToRemove {
 			pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
 			if err != nil {
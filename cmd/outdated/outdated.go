@@ -0,0 +1,172 @@
+package outdated
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"webman/pkgparse"
+	"webman/utils"
+	"webman/vercmp"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// How many packages have their latest version looked up at once
+const maxWorkers = 8
+
+var jsonFlag bool
+
+type pkgStatus struct {
+	Package         string `json:"package"`
+	Using           string `json:"using"`
+	NewestInstalled string `json:"newest_installed"`
+	Latest          string `json:"latest,omitempty"`
+	Outdated        bool   `json:"outdated"`
+	Error           string `json:"error,omitempty"`
+}
+
+var OutdatedCmd = &cobra.Command{
+	Use:   "outdated [pkgs...]",
+	Short: "list in-use packages with newer versions available",
+	Long: `
+The "outdated" subcommand checks the in-use version of every installed package, or only the given packages,
+against the latest version available, and lists the ones that are behind.
+It exits with code 2 if a package could not be checked, otherwise 1 if any package is outdated.`,
+	Example: `webman outdated
+webman outdated go node
+webman outdated --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Init()
+		pkgs := args
+		if len(pkgs) == 0 {
+			entries, err := os.ReadDir(utils.WebmanPkgDir)
+			if err != nil && !os.IsNotExist(err) {
+				color.Red("Unable to read package directory: %v", err)
+				os.Exit(1)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					pkgs = append(pkgs, entry.Name())
+				}
+			}
+		}
+		var statuses []*pkgStatus
+		for _, pkg := range pkgs {
+			using, err := pkgparse.CheckUsing(pkg)
+			if err != nil {
+				statuses = append(statuses, &pkgStatus{Package: pkg, Error: err.Error()})
+				continue
+			}
+			if using == nil {
+				if len(args) != 0 {
+					statuses = append(statuses, &pkgStatus{Package: pkg,
+						Error: fmt.Sprintf("not currently using any %s version", pkg)})
+				}
+				continue
+			}
+			_, ver := utils.ParseStem(*using)
+			status := pkgStatus{Package: pkg, Using: ver, NewestInstalled: ver}
+			if installed, err := pkgparse.InstalledVersions(pkg); err == nil && len(installed) != 0 {
+				status.NewestInstalled = installed[0]
+			}
+			statuses = append(statuses, &status)
+		}
+		checkLatest(statuses)
+
+		outdated, failed := 0, 0
+		for _, status := range statuses {
+			if status.Error != "" {
+				failed++
+			} else if status.Outdated {
+				outdated++
+			}
+		}
+		if jsonFlag {
+			if statuses == nil {
+				statuses = []*pkgStatus{}
+			}
+			data, err := json.MarshalIndent(statuses, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printTable(statuses, outdated)
+		}
+		// a package that couldn't be checked may be outdated too, so failures take priority
+		if failed != 0 {
+			os.Exit(2)
+		}
+		if outdated != 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// Looks up the latest version of each package using a bounded pool of workers,
+// recording any error on the package's status
+func checkLatest(statuses []*pkgStatus) {
+	jobs := make(chan *pkgStatus)
+	var wg sync.WaitGroup
+	for i := 0; i < maxWorkers && i < len(statuses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for status := range jobs {
+				pkgConf, err := pkgparse.ParsePkgConfigLocal(status.Package, false)
+				if err != nil {
+					status.Error = err.Error()
+					continue
+				}
+				latest, err := pkgConf.GetLatestVersion()
+				if err != nil {
+					status.Error = fmt.Sprintf("unable to find latest version: %v", err)
+					continue
+				}
+				status.Latest = *latest
+				status.Outdated = vercmp.Compare(*latest, status.Using) > 0
+			}
+		}()
+	}
+	for _, status := range statuses {
+		if status.Error == "" {
+			jobs <- status
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func printTable(statuses []*pkgStatus, outdated int) {
+	if outdated != 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tIN USE\tNEWEST INSTALLED\tLATEST")
+		for _, status := range statuses {
+			if status.Outdated {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					status.Package, status.Using, status.NewestInstalled, status.Latest)
+			}
+		}
+		w.Flush()
+	}
+	checked := 0
+	for _, status := range statuses {
+		if status.Error != "" {
+			fmt.Fprintln(os.Stderr, color.RedString("%s: %s", status.Package, status.Error))
+		} else {
+			checked++
+		}
+	}
+	if outdated == 0 && checked != 0 {
+		color.Green("All %d checked packages are up to date.", checked)
+	} else if checked == 0 && len(statuses) == 0 {
+		color.HiBlack("No packages are currently in use.")
+	}
+}
+
+func init() {
+	OutdatedCmd.Flags().BoolVar(&jsonFlag, "json", false, "print the package versions as JSON")
+}
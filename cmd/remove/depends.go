@@ -0,0 +1,54 @@
+package remove
+
+import (
+	"strings"
+	"webman/pkgparse"
+
+	"github.com/fatih/color"
+)
+
+// Warns about installed packages whose dependency on pkg would no longer be satisfied
+// after removing the given version stems. Dependents in alsoRemoving are left out.
+// Returns whether any dependents would break.
+func WarnDependents(pkg string, pkgVerStems []string, alsoRemoving []string) (bool, error) {
+	dependents, err := pkgparse.FindDependents(pkg)
+	if err != nil || len(dependents) == 0 {
+		return false, err
+	}
+	installed, err := pkgparse.InstalledVersions(pkg)
+	if err != nil {
+		return false, err
+	}
+	removing := map[string]bool{}
+	for _, pkgVerStem := range pkgVerStems {
+		removing[strings.TrimPrefix(pkgVerStem, pkg+"-")] = true
+	}
+	var remaining []string
+	for _, ver := range installed {
+		if !removing[ver] {
+			remaining = append(remaining, ver)
+		}
+	}
+	skip := map[string]bool{}
+	for _, other := range alsoRemoving {
+		skip[other] = true
+	}
+	breaks := false
+	for _, dependent := range dependents {
+		if skip[dependent.Pkg] {
+			continue
+		}
+		satisfied := false
+		for _, ver := range remaining {
+			if dependent.Dep.Check(ver) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			color.Yellow("%s depends on %s", color.CyanString(dependent.Pkg), dependent.Dep.String())
+			breaks = true
+		}
+	}
+	return breaks, nil
+}
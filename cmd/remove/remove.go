@@ -30,15 +30,14 @@ webman remove rg`,
 		}
 		pkg := args[0]
 
-		pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
-		dirEntries, err := os.ReadDir(pkgDir)
+		installedVers, err := pkgparse.InstalledVersions(pkg)
 		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Printf("No versions of %s are currently installed.\n", color.CyanString(pkg))
-				os.Exit(0)
-			}
 			panic(err)
 		}
+		if len(installedVers) == 0 {
+			fmt.Printf("No versions of %s are currently installed.\n", color.CyanString(pkg))
+			os.Exit(0)
+		}
 		using, err := pkgparse.CheckUsing(pkg)
 		if err != nil {
 			panic(err)
@@ -49,10 +48,14 @@ webman remove rg`,
 			fmt.Printf("Not currently using any %s version\n", color.CyanString(pkg))
 		}
 
-		var pkgVersions []string
-		for _, entry := range dirEntries {
-			if entry.IsDir() {
-				pkgVersions = append(pkgVersions, entry.Name())
+		// newest first, with the version in use marked
+		pkgVersions := make([]string, len(installedVers))
+		options := make([]string, len(installedVers))
+		for i, ver := range installedVers {
+			pkgVersions[i] = utils.CreateStem(pkg, ver)
+			options[i] = pkgVersions[i]
+			if using != nil && *using == pkgVersions[i] {
+				options[i] += color.HiBlackString(" (in use)")
 			}
 		}
 		var pkgVerStems []string
@@ -61,19 +64,41 @@ webman remove rg`,
 		} else {
 			surveyPrompt := &survey.MultiSelect{
 				Message:  "Select " + color.CyanString(pkg) + " version to " + color.RedString("remove") + ":",
-				Options:  pkgVersions,
+				Options:  options,
 				PageSize: 10,
 			}
-			err := survey.AskOne(surveyPrompt, &pkgVerStems)
+			var indices []int
+			err := survey.AskOne(surveyPrompt, &indices)
 			if err != nil {
 				fmt.Printf("Prompt failed %v\n", err)
 				return
 			}
+			for _, idx := range indices {
+				pkgVerStems = append(pkgVerStems, pkgVersions[idx])
+			}
 		}
 		if len(pkgVerStems) == 0 {
 			color.HiBlack("No packages selected for removal.")
 			os.Exit(0)
 		}
+		breaks, err := WarnDependents(pkg, pkgVerStems, nil)
+		if err != nil {
+			panic(err)
+		}
+		if breaks {
+			proceed := false
+			prompt := &survey.Confirm{
+				Message: "Remove anyway?",
+			}
+			if err := survey.AskOne(prompt, &proceed); err != nil {
+				fmt.Printf("Prompt failed %v\n", err)
+				return
+			}
+			if !proceed {
+				color.HiBlack("No packages removed.")
+				os.Exit(0)
+			}
+		}
 		pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
 		if err != nil {
 			panic(err)
@@ -85,7 +110,10 @@ webman remove rg`,
 			}
 		} else {
 			for _, pkgVerStem := range pkgVerStems {
-				RemovePkgVer(pkgVerStem, using, pkg, pkgConf)
+				if err := RemovePkgVer(pkgVerStem, using, pkg, pkgConf); err != nil {
+					color.Red("Unable to remove %s: %v", pkgVerStem, err)
+					os.Exit(1)
+				}
 			}
 		}
 		fmt.Printf("All %d selected packages are uninstalled.\n", len(pkgVerStems))
@@ -121,6 +149,9 @@ func UninstallBins(pkg string, pkgConf *pkgparse.PkgConfig) error {
 			panic(err)
 		}
 	}
+	if err = link.RemoveShareLinks(pkg, ver, pkgConf); err != nil {
+		return err
+	}
 	fmt.Printf("%s%sRemoved %s links!\n", multiline.MoveUp, multiline.ClearLine, color.CyanString(pkg))
 	if err = pkgparse.RemoveUsing(pkg); err != nil {
 		return err
@@ -128,19 +159,20 @@ func UninstallBins(pkg string, pkgConf *pkgparse.PkgConfig) error {
 	return nil
 }
 
-func RemovePkgVer(pkgVerStem string, using *string, pkg string, pkgConf *pkgparse.PkgConfig) {
+// Removes an installed package version, uninstalling its binaries first if it's in use
+func RemovePkgVer(pkgVerStem string, using *string, pkg string, pkgConf *pkgparse.PkgConfig) error {
 	// if the selected pkgVerStem is being used, uninstall bins
 	if using != nil && *using == pkgVerStem {
 		if err := UninstallBins(pkg, pkgConf); err != nil {
-			color.Red("Error uninstalling binaries: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("error uninstalling binaries: %v", err)
 		}
 	}
 	fmt.Printf("Removing %s ...\n", pkgVerStem)
 	if err := os.RemoveAll(filepath.Join(utils.WebmanPkgDir, pkg, pkgVerStem)); err != nil {
-		panic(err)
+		return err
 	}
 	fmt.Printf("%s%sRemoved %s!\n", multiline.MoveUp, multiline.ClearLine, pkgVerStem)
+	return nil
 }
 
 func RemoveAllVers(pkg string, pkgConf *pkgparse.PkgConfig) (bool, error) {
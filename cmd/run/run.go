@@ -24,6 +24,7 @@ or a binary name given after the colon.`,
 	Example: `webman run go
 webman run bat [FILE]
 webman run go@18.0.0
+webman run go@1.18
 webman run node@17.0.0 --version
 webman run node@17.0.0:npm --version
 webman run node:npm --version`,
@@ -92,7 +93,11 @@ func runPackage(args []string) {
 	// Is custom version
 	var pkgDirName string
 	if ver != "" {
-		pkgDirName = fmt.Sprintf("%s-%s", pkg, ver)
+		resolved, err := pkgConf.ResolveInstalledVersion(ver)
+		if err != nil {
+			exitPrint(1, color.RedString(err.Error()))
+		}
+		pkgDirName = utils.CreateStem(pkg, *resolved)
 	} else { // Default version
 		usingVersion, err := pkgparse.CheckUsing(pkg)
 		if err != nil {
@@ -176,7 +181,12 @@ func runPackage(args []string) {
 	appCmd.Stderr = os.Stderr
 	appCmd.Stdout = os.Stdout
 	appCmd.Stdin = os.Stdin
-	appCmd.Env = os.Environ()
+	_, runVer := utils.ParseStem(pkgDirName)
+	env, err := pkgConf.GetEnv(runVer)
+	if err != nil {
+		exitPrint(1, color.RedString(err.Error()))
+	}
+	appCmd.Env = append(os.Environ(), env...)
 
 	// Start package
 	if err := appCmd.Run(); err != nil {
@@ -3,7 +3,6 @@ package switchcmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"webman/link"
 	"webman/pkgparse"
 	"webman/utils"
@@ -15,28 +14,34 @@ import (
 
 // SwitchCmd represents the remove command
 var SwitchCmd = &cobra.Command{
-	Use:   "switch [pkg]",
+	Use:   "switch [pkg](@[version])",
 	Short: "switch to a specific version of a package",
-	Long:  `The "switch" subcommand changes path to a prompt-selected version of a given package.`,
+	Long: `The "switch" subcommand changes path to a prompt-selected version of a given package,
+or to the highest installed version matching the given version constraint.`,
 	Example: `webman switch go
 webman switch zig
-webman switch rg`,
+webman switch rg
+webman switch go@1.18
+webman switch node@^16`,
 	Run: func(cmd *cobra.Command, args []string) {
 		utils.Init()
 		if len(args) != 1 {
 			cmd.Help()
 			os.Exit(0)
 		}
-		pkg := args[0]
-		pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
-		dirEntries, err := os.ReadDir(pkgDir)
+		pkg, spec, err := utils.ParsePkgVer(args[0])
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		installedVers, err := pkgparse.InstalledVersions(pkg)
 		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Printf("No versions of %s are currently installed.\n", color.CyanString(pkg))
-				os.Exit(0)
-			}
 			panic(err)
 		}
+		if len(installedVers) == 0 {
+			fmt.Printf("No versions of %s are currently installed.\n", color.CyanString(pkg))
+			os.Exit(0)
+		}
 
 		using, err := pkgparse.CheckUsing(pkg)
 		if err != nil {
@@ -48,10 +53,16 @@ webman switch rg`,
 			fmt.Printf("Not currently using any %s version\n", color.CyanString(pkg))
 		}
 
-		var pkgVersions []string
-		for _, entry := range dirEntries {
-			if entry.IsDir() {
-				pkgVersions = append(pkgVersions, entry.Name())
+		// newest first, with the version in use marked and selected by default
+		pkgVersions := make([]string, len(installedVers))
+		options := make([]string, len(installedVers))
+		defaultIdx := 0
+		for i, ver := range installedVers {
+			pkgVersions[i] = utils.CreateStem(pkg, ver)
+			options[i] = pkgVersions[i]
+			if using != nil && *using == pkgVersions[i] {
+				options[i] += color.HiBlackString(" (in use)")
+				defaultIdx = i
 			}
 		}
 		pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
@@ -60,7 +71,18 @@ webman switch rg`,
 			os.Exit(1)
 		}
 		var pkgVerStem string
-		if len(pkgVersions) == 1 {
+		if spec != "" {
+			ver, err := pkgConf.ResolveInstalledVersion(spec)
+			if err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
+			pkgVerStem = utils.CreateStem(pkg, *ver)
+			if using != nil && *using == pkgVerStem {
+				fmt.Printf("%s is already in use.\n", pkgVerStem)
+				os.Exit(0)
+			}
+		} else if len(pkgVersions) == 1 {
 			pkgVerStem = pkgVersions[0]
 			if using != nil && *using == pkgVerStem {
 				fmt.Printf("Only one version of %s installed, which is already in use.\n", pkg)
@@ -69,13 +91,16 @@ webman switch rg`,
 		} else {
 			surveyPrompt := &survey.Select{
 				Message: "Select " + color.CyanString(pkg) + " version to switch to use:",
-				Options: pkgVersions,
+				Options: options,
+				Default: options[defaultIdx],
 			}
-			err := survey.AskOne(surveyPrompt, &pkgVerStem)
+			var idx int
+			err := survey.AskOne(surveyPrompt, &idx)
 			if err != nil {
 				fmt.Printf("Prompt failed %v\n", err)
 				os.Exit(1)
 			}
+			pkgVerStem = pkgVersions[idx]
 		}
 		binPaths, err := pkgConf.GetMyBinPaths()
 		if err != nil {
@@ -83,15 +108,25 @@ webman switch rg`,
 			return
 		}
 		_, ver := utils.ParseStem(pkgVerStem)
-		madeLinks, err := link.CreateLinks(pkg, ver, binPaths)
-		if err != nil {
-			panic(err)
+		if err = link.ReplaceShareLinks(pkg, using, ver, pkgConf); err != nil {
+			link.RestoreLinks(pkg, using, ver, binPaths, pkgConf)
+			color.Red("Failed creating completion and man page links: %v", err)
+			os.Exit(1)
 		}
-		if !madeLinks {
-			panic("Unable to create all links")
+		madeLinks, err := link.CreateLinks(pkg, ver, binPaths)
+		if err != nil || !madeLinks {
+			link.RestoreLinks(pkg, using, ver, binPaths, pkgConf)
+			if err == nil {
+				err = fmt.Errorf("unable to create all links")
+			}
+			color.Red("Failed creating links: %v", err)
+			os.Exit(1)
 		}
 		fmt.Printf("Created links for %s\n", pkgVerStem)
 		color.Green("Successfully switched, %s now using %s\n", pkg, color.CyanString(pkgVerStem))
+		if len(pkgConf.Env) != 0 {
+			color.HiBlack("Run 'eval \"$(webman env)\"' to update %s environment variables", pkg)
+		}
 	},
 }
 
@@ -0,0 +1,330 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"webman/cmd/add"
+	"webman/cmd/remove"
+	"webman/link"
+	"webman/pkgparse"
+	"webman/utils"
+	"webman/vercmp"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// How many packages have their latest version looked up at once
+const maxWorkers = 8
+
+var allFlag bool
+var keepFlag int
+var removePreviousFlag bool
+var doRefresh bool
+
+// What happened to a package during the upgrade
+type upgradeResult struct {
+	pkg     string
+	pkgConf *pkgparse.PkgConfig
+	from    string
+	to      string
+	// whether the upgrade was already installed, so it isn't removed if switching fails
+	preinstalled bool
+	removed      []string
+	note         string
+	err          error
+}
+
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade [pkgs...]",
+	Short: "upgrade packages to their latest version",
+	Long: `
+The "upgrade" subcommand installs the latest version of in-use packages and switches to it.
+Previous versions are kept unless --keep or --remove-previous is given.
+Packages that fail to upgrade are left as they were.`,
+	Example: `webman upgrade go rg
+webman upgrade --all
+webman upgrade node@lts
+webman upgrade --all --keep 2
+webman upgrade zig --remove-previous`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Init()
+		if (len(args) == 0) == !allFlag {
+			cmd.Help()
+			os.Exit(0)
+		}
+		if keepFlag < 0 {
+			color.Red("--keep can't be negative")
+			os.Exit(1)
+		}
+		if keepFlag != 0 && removePreviousFlag {
+			color.Red("--keep and --remove-previous can't be used together")
+			os.Exit(1)
+		}
+		defer os.RemoveAll(utils.WebmanTmpDir)
+		add.RefreshRecipesIfStale(doRefresh)
+		if allFlag {
+			args = inUsePkgs()
+			if len(args) == 0 {
+				color.HiBlack("No packages are currently in use.")
+				return
+			}
+		}
+		results := findUpgrades(args)
+
+		var installArgs []string
+		for _, res := range results {
+			if res.err == nil && res.to != "" {
+				installArgs = append(installArgs, res.pkg+"@"+res.to)
+			}
+		}
+		if len(installArgs) != 0 {
+			failed, err := add.InstallPkgsReportFailed(installArgs)
+			for _, res := range results {
+				if res.err != nil || res.to == "" {
+					continue
+				}
+				if err != nil {
+					res.err = err
+				} else if failed[res.pkg] {
+					res.err = fmt.Errorf("failed to install %s", res.to)
+				} else {
+					res.finish()
+				}
+			}
+		}
+		if !printSummary(results) {
+			os.Exit(1)
+		}
+	},
+}
+
+// Returns the installed packages that have a version in use
+func inUsePkgs() []string {
+	entries, err := os.ReadDir(utils.WebmanPkgDir)
+	if err != nil && !os.IsNotExist(err) {
+		color.Red("Unable to read package directory: %v", err)
+		os.Exit(1)
+	}
+	var pkgs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if using, err := pkgparse.CheckUsing(entry.Name()); err == nil && using != nil {
+			pkgs = append(pkgs, entry.Name())
+		}
+	}
+	return pkgs
+}
+
+// Finds the version each package should be upgraded to, using a bounded pool of workers.
+// Packages already at that version are left with an empty target.
+func findUpgrades(args []string) []*upgradeResult {
+	results := make([]*upgradeResult, len(args))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	color.HiBlue("Checking for newer versions of %d packages", len(args))
+	for i := 0; i < maxWorkers && i < len(args); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = findUpgrade(args[i])
+			}
+		}()
+	}
+	for i := range args {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func findUpgrade(arg string) *upgradeResult {
+	pkg, spec, err := utils.ParsePkgVer(arg)
+	if err != nil {
+		return &upgradeResult{pkg: arg, err: err}
+	}
+	res := upgradeResult{pkg: pkg}
+	using, err := pkgparse.CheckUsing(pkg)
+	if err != nil {
+		res.err = err
+		return &res
+	}
+	if using == nil {
+		res.err = fmt.Errorf("not currently using any %s version", pkg)
+		return &res
+	}
+	_, res.from = utils.ParseStem(*using)
+	if res.pkgConf, err = pkgparse.ParsePkgConfigLocal(pkg, false); err != nil {
+		res.err = err
+		return &res
+	}
+	vs, err := res.pkgConf.ParseVersionSpec(spec)
+	if err != nil {
+		res.err = err
+		return &res
+	}
+	latest, err := res.pkgConf.ResolveSpec(vs)
+	if err != nil {
+		res.err = fmt.Errorf("unable to find latest version: %v", err)
+		return &res
+	}
+	if vercmp.Compare(*latest, res.from) > 0 {
+		res.to = *latest
+		_, err = os.Stat(filepath.Join(utils.WebmanPkgDir, pkg, utils.CreateStem(pkg, res.to)))
+		res.preinstalled = err == nil
+	}
+	return &res
+}
+
+// Switches to the installed upgrade and applies the retention policy.
+// If switching fails, the links of the previous version are restored,
+// and the upgrade is removed unless it was installed before.
+func (res *upgradeResult) finish() {
+	binPaths, err := res.pkgConf.GetMyBinPaths()
+	if err != nil {
+		res.err = fmt.Errorf("failed switching to %s: %v", res.to, err)
+		return
+	}
+	fromStem := utils.CreateStem(res.pkg, res.from)
+	if err = switchVersion(res.pkg, fromStem, res.to, binPaths, res.pkgConf); err != nil {
+		res.err = fmt.Errorf("failed switching to %s: %v", res.to, err)
+		if err = link.RestoreLinks(res.pkg, &fromStem, res.to, binPaths, res.pkgConf); err != nil {
+			res.note = fmt.Sprintf("unable to restore links of %s: %v", res.from, err)
+		}
+		if !res.preinstalled {
+			os.RemoveAll(filepath.Join(utils.WebmanPkgDir, res.pkg, utils.CreateStem(res.pkg, res.to)))
+		}
+		return
+	}
+	installed, err := pkgparse.InstalledVersions(res.pkg)
+	if err != nil {
+		res.note = fmt.Sprintf("unable to apply retention policy: %v", err)
+		return
+	}
+	var removeVers []string
+	for i, ver := range installed {
+		if ver == res.to {
+			continue
+		}
+		if (removePreviousFlag && ver == res.from) || (keepFlag != 0 && i >= keepFlag) {
+			removeVers = append(removeVers, ver)
+		}
+	}
+	if len(removeVers) == 0 {
+		return
+	}
+	removeVers, kept, err := keepDependedOn(res.pkg, installed, removeVers)
+	if err != nil {
+		res.note = fmt.Sprintf("unable to check dependents, kept previous versions: %v", err)
+		return
+	}
+	var notes []string
+	if len(kept) != 0 {
+		notes = append(notes, fmt.Sprintf("kept %s, which other packages depend on", strings.Join(kept, ", ")))
+	}
+	toStem := utils.CreateStem(res.pkg, res.to)
+	for _, ver := range removeVers {
+		if err := remove.RemovePkgVer(utils.CreateStem(res.pkg, ver), &toStem, res.pkg, res.pkgConf); err != nil {
+			notes = append(notes, fmt.Sprintf("unable to remove %s: %v", ver, err))
+			continue
+		}
+		res.removed = append(res.removed, ver)
+	}
+	res.note = strings.Join(notes, "; ")
+}
+
+// Splits the versions to remove into those that can go and those that must be kept,
+// keeping the newest version each dependent needs if no other remaining version satisfies it
+func keepDependedOn(pkg string, installed []string, removeVers []string) ([]string, []string, error) {
+	dependents, err := pkgparse.FindDependents(pkg)
+	if err != nil || len(dependents) == 0 {
+		return removeVers, nil, err
+	}
+	removing := map[string]bool{}
+	for _, ver := range removeVers {
+		removing[ver] = true
+	}
+	for _, dependent := range dependents {
+		satisfied := false
+		for _, ver := range installed {
+			if !removing[ver] && dependent.Dep.Check(ver) {
+				satisfied = true
+				break
+			}
+		}
+		if satisfied {
+			continue
+		}
+		// installed versions are sorted newest first
+		for _, ver := range installed {
+			if removing[ver] && dependent.Dep.Check(ver) {
+				removing[ver] = false
+				break
+			}
+		}
+	}
+	var remaining, kept []string
+	for _, ver := range removeVers {
+		if removing[ver] {
+			remaining = append(remaining, ver)
+		} else {
+			kept = append(kept, ver)
+		}
+	}
+	return remaining, kept, nil
+}
+
+// Replaces the links of the from version of a package with links to the to version
+func switchVersion(pkg string, fromStem string, to string, binPaths []string, pkgConf *pkgparse.PkgConfig) error {
+	if err := link.ReplaceShareLinks(pkg, &fromStem, to, pkgConf); err != nil {
+		return err
+	}
+	madeLinks, err := link.CreateLinks(pkg, to, binPaths)
+	if err != nil {
+		return err
+	}
+	if !madeLinks {
+		return fmt.Errorf("unable to create all links")
+	}
+	return nil
+}
+
+// Prints what happened to each package, returning whether every upgrade succeeded
+func printSummary(results []*upgradeResult) bool {
+	success := true
+	fmt.Println("Upgrade summary:")
+	for _, res := range results {
+		line := "  " + color.CyanString(res.pkg) + ": "
+		switch {
+		case res.err != nil:
+			success = false
+			line += color.RedString("%v", res.err)
+		case res.to == "":
+			line += color.HiBlackString("%s is up to date", res.from)
+		default:
+			line += color.MagentaString(res.from) + " -> " + color.GreenString(res.to)
+			if len(res.removed) != 0 {
+				line += color.HiBlackString(" (removed %s)", strings.Join(res.removed, ", "))
+			}
+		}
+		if res.note != "" {
+			line += color.YellowString(" (%s)", res.note)
+		}
+		fmt.Println(line)
+	}
+	return success
+}
+
+func init() {
+	UpgradeCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "upgrade every package in use")
+	UpgradeCmd.Flags().IntVar(&keepFlag, "keep", 0, "keep only the N most recent installed versions of each upgraded package")
+	UpgradeCmd.Flags().BoolVar(&removePreviousFlag, "remove-previous", false, "remove the previously used version after upgrading")
+	UpgradeCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
+}
@@ -0,0 +1,110 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"webman/pkgparse"
+	"webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var jsonFlag bool
+
+type versionInfo struct {
+	pkgparse.RemoteVersion
+	Installed bool `json:"installed"`
+	Using     bool `json:"using"`
+}
+
+var VersionsCmd = &cobra.Command{
+	Use:   "versions [pkg]",
+	Short: "list the available versions of a package",
+	Long: `
+The "versions" subcommand lists the versions of a package that can be installed, newest first,
+and marks which are installed and which one is in use.`,
+	Example: `webman versions go
+webman versions rg --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Init()
+		if len(args) != 1 {
+			cmd.Help()
+			os.Exit(0)
+		}
+		pkg := args[0]
+		pkgConf, err := pkgparse.ParsePkgConfigLocal(pkg, false)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		remoteVers, err := pkgConf.ListVersions()
+		if err != nil {
+			color.Red("Unable to list %s versions: %v", pkg, err)
+			os.Exit(1)
+		}
+		installedVers, err := pkgparse.InstalledVersions(pkg)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		installed := map[string]bool{}
+		for _, ver := range installedVers {
+			installed[ver] = true
+		}
+		using, err := pkgparse.CheckUsing(pkg)
+		if err != nil {
+			color.Red("%v", err)
+			os.Exit(1)
+		}
+		var usingVer string
+		if using != nil {
+			usingVer = strings.TrimPrefix(*using, pkg+"-")
+		}
+		vers := make([]versionInfo, len(remoteVers))
+		for i, remoteVer := range remoteVers {
+			vers[i] = versionInfo{
+				RemoteVersion: remoteVer,
+				Installed:     installed[remoteVer.Version],
+				Using:         remoteVer.Version == usingVer,
+			}
+		}
+		if jsonFlag {
+			data, err := json.MarshalIndent(vers, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		if len(vers) == 0 {
+			color.HiBlack("No versions of %s found.", pkg)
+			return
+		}
+		for _, ver := range vers {
+			var labels []string
+			if ver.Using {
+				labels = append(labels, color.GreenString("in use"))
+			} else if ver.Installed {
+				labels = append(labels, color.CyanString("installed"))
+			}
+			if ver.Lts {
+				labels = append(labels, color.YellowString("lts"))
+			}
+			if ver.Prerelease {
+				labels = append(labels, color.HiBlackString("prerelease"))
+			}
+			line := color.MagentaString(ver.Version)
+			if len(labels) != 0 {
+				line += " (" + strings.Join(labels, ", ") + ")"
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+func init() {
+	VersionsCmd.Flags().BoolVar(&jsonFlag, "json", false, "print the versions as JSON")
+}
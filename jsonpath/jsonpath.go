@@ -0,0 +1,351 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	childStep stepKind = iota
+	indexStep
+	wildcardStep
+	filterStep
+)
+
+type step struct {
+	kind   stepKind
+	name   string
+	index  int
+	filter *filter
+}
+
+// A filter like [?(@.stable)] or [?(@.channel == 'lts')]
+type filter struct {
+	negate bool
+	fields []string
+	op     string
+	value  interface{}
+}
+
+// A compiled selector, a subset of JSONPath supporting
+// $.a.b, $['a'], [0], [-1], [*], .* and filters like [?(@.a.b)], [?(!@.a)] and [?(@.a >= 2)]
+type Path struct {
+	expr  string
+	steps []step
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Compiles a selector expression, which must start with $
+func Compile(expr string) (*Path, error) {
+	p := Path{expr: expr}
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("selector %q must start with $", expr)
+	}
+	s = s[1:]
+	for len(s) > 0 {
+		var st step
+		var err error
+		switch s[0] {
+		case '.':
+			st, s, err = parseDot(s[1:])
+		case '[':
+			st, s, err = parseBracket(s[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", s[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", expr, err)
+		}
+		p.steps = append(p.steps, st)
+	}
+	return &p, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Reads a field name, returning it with the rest of the string
+func readName(s string) (string, string) {
+	i := 0
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func parseDot(s string) (step, string, error) {
+	if strings.HasPrefix(s, "*") {
+		return step{kind: wildcardStep}, s[1:], nil
+	}
+	name, rest := readName(s)
+	if name == "" {
+		return step{}, "", fmt.Errorf("missing field name after '.'")
+	}
+	return step{kind: childStep, name: name}, rest, nil
+}
+
+func parseBracket(s string) (step, string, error) {
+	end := strings.Index(s, "]")
+	switch {
+	case strings.HasPrefix(s, "?("):
+		end = filterEnd(s)
+		if end < 0 {
+			return step{}, "", fmt.Errorf("unterminated filter")
+		}
+		f, err := parseFilter(s[2:end])
+		if err != nil {
+			return step{}, "", err
+		}
+		return step{kind: filterStep, filter: f}, s[end+2:], nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		quote := s[:1]
+		closing := strings.Index(s[1:], quote+"]")
+		if closing < 0 {
+			return step{}, "", fmt.Errorf("unterminated field name")
+		}
+		return step{kind: childStep, name: s[1 : closing+1]}, s[closing+3:], nil
+	case end < 0:
+		return step{}, "", fmt.Errorf("missing ']'")
+	case strings.TrimSpace(s[:end]) == "*":
+		return step{kind: wildcardStep}, s[end+1:], nil
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(s[:end]))
+	if err != nil {
+		return step{}, "", fmt.Errorf("invalid index %q", s[:end])
+	}
+	return step{kind: indexStep, index: index}, s[end+1:], nil
+}
+
+// Finds the ")]" closing a filter, skipping any inside quoted literals like 'a)]b'
+func filterEnd(s string) int {
+	var quote byte
+	for i := 2; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '\'' || s[i] == '"':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], ")]"):
+			return i
+		}
+	}
+	return -1
+}
+
+var filterOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseFilter(s string) (*filter, error) {
+	var f filter
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "!") {
+		f.negate = true
+		s = strings.TrimSpace(s[1:])
+	}
+	if !strings.HasPrefix(s, "@") {
+		return nil, fmt.Errorf("filter must test a field of @")
+	}
+	s = s[1:]
+	for strings.HasPrefix(s, ".") {
+		var name string
+		name, s = readName(s[1:])
+		if name == "" {
+			return nil, fmt.Errorf("missing field name after '.' in filter")
+		}
+		f.fields = append(f.fields, name)
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return &f, nil
+	}
+	if f.negate {
+		return nil, fmt.Errorf("'!' can't be used with a comparison")
+	}
+	for _, op := range filterOps {
+		if strings.HasPrefix(s, op) {
+			f.op = op
+			break
+		}
+	}
+	if f.op == "" {
+		return nil, fmt.Errorf("unexpected %q in filter", s)
+	}
+	value, err := parseLiteral(strings.TrimSpace(s[len(f.op):]))
+	if err != nil {
+		return nil, err
+	}
+	f.value = value
+	return &f, nil
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	switch {
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s == "null":
+		return nil, nil
+	case len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]:
+		return s[1 : len(s)-1], nil
+	}
+	num, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q in filter", s)
+	}
+	return num, nil
+}
+
+// Decodes the JSON document and returns every value the selector matches
+func (p *Path) EvaluateJSON(data []byte) ([]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+	return p.Evaluate(root), nil
+}
+
+// Returns every value the selector matches in a decoded JSON document
+func (p *Path) Evaluate(root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, st.apply(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// Returns the elements of an array or the values of an object in key order
+func children(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case []interface{}:
+		return n
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for key := range n {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = n[key]
+		}
+		return values
+	}
+	return nil
+}
+
+func (st *step) apply(node interface{}) []interface{} {
+	switch st.kind {
+	case childStep:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if value, exists := obj[st.name]; exists {
+				return []interface{}{value}
+			}
+		}
+	case indexStep:
+		if arr, ok := node.([]interface{}); ok {
+			index := st.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}
+			}
+		}
+	case wildcardStep:
+		return children(node)
+	case filterStep:
+		var matched []interface{}
+		for _, child := range children(node) {
+			if st.filter.matches(child) {
+				matched = append(matched, child)
+			}
+		}
+		return matched
+	}
+	return nil
+}
+
+func (f *filter) matches(node interface{}) bool {
+	value := node
+	for _, field := range f.fields {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return f.negate
+		}
+		if value, ok = obj[field]; !ok {
+			return f.negate
+		}
+	}
+	if f.op == "" {
+		truthy := value != nil && value != false
+		return truthy != f.negate
+	}
+	return compare(value, f.op, f.value)
+}
+
+func compare(value interface{}, op string, literal interface{}) bool {
+	if num, ok := value.(json.Number); ok {
+		if parsed, err := num.Float64(); err == nil {
+			value = parsed
+		}
+	}
+	var cmp int
+	switch lit := literal.(type) {
+	case float64:
+		num, ok := value.(float64)
+		if !ok {
+			return op == "!="
+		}
+		switch {
+		case num < lit:
+			cmp = -1
+		case num > lit:
+			cmp = 1
+		}
+	case string:
+		str, ok := value.(string)
+		if !ok {
+			return op == "!="
+		}
+		cmp = strings.Compare(str, lit)
+	default:
+		// booleans and null only support equality
+		equal := value == literal
+		return (op == "==" && equal) || (op == "!=" && !equal)
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
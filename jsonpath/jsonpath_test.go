@@ -0,0 +1,122 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testDoc = `{
+	"name": "tool",
+	"latest": {"version": "1.2.0", "meta": {"lts": true}},
+	"a.b": "dotted",
+	"releases": [
+		{"version": "1.2.0", "stable": true, "channel": "lts", "downloads": 30, "name": "a)]b"},
+		{"version": "1.3.0-rc1", "stable": false, "channel": "next", "downloads": 5, "name": "plain"},
+		{"version": "1.1.0", "stable": true, "channel": null, "downloads": 100, "name": "x"}
+	]
+}`
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		// dot and bracket access
+		{"$.name", []string{"tool"}},
+		{"$.latest.version", []string{"1.2.0"}},
+		{"$['latest']['version']", []string{"1.2.0"}},
+		{`$["latest"].meta.lts`, []string{"true"}},
+		{"$['a.b']", []string{"dotted"}},
+		{"$.missing", nil},
+		{"$.name.version", nil},
+		// indexes
+		{"$.releases[0].version", []string{"1.2.0"}},
+		{"$.releases[-1].version", []string{"1.1.0"}},
+		{"$.releases[ 1 ].version", []string{"1.3.0-rc1"}},
+		{"$.releases[3].version", nil},
+		{"$.releases[-4].version", nil},
+		// wildcards
+		{"$.releases[*].version", []string{"1.2.0", "1.3.0-rc1", "1.1.0"}},
+		{"$.releases.*.downloads", []string{"30", "5", "100"}},
+		{"$.latest.*", []string{"{lts:true}", "1.2.0"}},
+		// filters
+		{"$.releases[?(@.stable)].version", []string{"1.2.0", "1.1.0"}},
+		{"$.releases[?(!@.stable)].version", []string{"1.3.0-rc1"}},
+		{"$.releases[?(@.channel)].version", []string{"1.2.0", "1.3.0-rc1"}},
+		{"$.releases[?(@.channel == 'lts')].version", []string{"1.2.0"}},
+		{`$.releases[?(@.channel != "lts")].version`, []string{"1.3.0-rc1", "1.1.0"}},
+		{"$.releases[?(@.channel == null)].version", []string{"1.1.0"}},
+		{"$.releases[?(@.stable == false)].version", []string{"1.3.0-rc1"}},
+		{"$.releases[?(@.downloads >= 30)].version", []string{"1.2.0", "1.1.0"}},
+		{"$.releases[?(@.downloads < 30)].version", []string{"1.3.0-rc1"}},
+		{"$.releases[?(@.version > '1.1.0')].version", []string{"1.2.0", "1.3.0-rc1"}},
+		{`$.releases[?(@.name=="a)]b")].version`, []string{"1.2.0"}},
+		{"$.releases[?(@.name == 'a)]b')].downloads", []string{"30"}},
+		{"$[?(@.meta.lts)].version", []string{"1.2.0"}},
+		{"$.latest[?(@.lts)]", []string{"{lts:true}"}},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		values, err := p.EvaluateJSON([]byte(testDoc))
+		if err != nil {
+			t.Fatalf("EvaluateJSON(%q): %v", tt.expr, err)
+		}
+		var got []string
+		for _, value := range values {
+			got = append(got, format(value))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"name",
+		"$.",
+		"$..name",
+		"$[0",
+		"$[abc]",
+		"$['name",
+		"$[?(@.a == 'b)]",
+		"$[?(@.a]",
+		"$[?(a)]",
+		"$[?(!@.a == 1)]",
+		"$[?(@.a ~ 1)]",
+		"$[?(@.a == nope)]",
+		"$name",
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+// Formats a decoded JSON value compactly, with object keys in sorted order
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			keys[i] = key + ":" + format(v[key])
+		}
+		return "{" + strings.Join(keys, ",") + "}"
+	case json.Number:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
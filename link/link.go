@@ -87,33 +87,55 @@ func GetLinkPathIfExec(binPath string) *string {
 
 // Create a link to an old file at the new path
 // On windows, .bat will be appended to the new path to make a batch file
+// The link is made under a temporary name and renamed over the new path,
+// so the new path never goes missing while switching versions
 func AddLink(old string, new string) (bool, error) {
 	if utils.GOOS == "windows" {
-		f, err := os.Create(new + ".bat")
+		new += ".bat"
+		tmp := tempLinkPath(new)
+		f, err := os.Create(tmp)
 		if err != nil {
 			return false, err
 		}
-		defer f.Close()
 		_, err = f.WriteString(
 			fmt.Sprintf("@echo off\n%s", old) + ` %*`,
 		)
+		f.Close()
 		if err != nil {
+			os.Remove(tmp)
 			return false, err
 		}
-	} else {
-		if err := os.Remove(new); err != nil {
-			// if the file did exist and it's a different error, return it
-			if !os.IsNotExist(err) {
-				return false, err
-			}
-		}
-		if err := os.Symlink(old, new); err != nil {
+		if err = os.Rename(tmp, new); err != nil {
+			os.Remove(tmp)
 			return false, err
 		}
+	} else if err := symlinkAtomic(old, new); err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
+// Returns a temporary path next to the given path, so renaming it over the path stays on one filesystem
+func tempLinkPath(path string) string {
+	return filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.tmp-%d", filepath.Base(path), os.Getpid()))
+}
+
+// Symlinks old at the new path by renaming a symlink with a temporary name over it
+func symlinkAtomic(old string, new string) error {
+	tmp := tempLinkPath(new)
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(old, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, new); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
 func CreateLinks(pkg string, ver string, confBinPaths []string) (bool, error) {
 	binPaths, linkPaths, err := GetBinPathsAndLinkPaths(pkg, ver, confBinPaths)
 	if err != nil {
@@ -0,0 +1,190 @@
+package link
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"webman/pkgparse"
+	"webman/utils"
+)
+
+// Returns the completion scripts and man pages a package version ships,
+// along with where each one is linked in ~/.webman/share
+func GetSharePathsAndLinkPaths(pkg string, ver string, pkgConf *pkgparse.PkgConfig) ([]string, []string, error) {
+	var sharePaths []string
+	var linkPaths []string
+	verDir := filepath.Join(utils.WebmanPkgDir, pkg, utils.CreateStem(pkg, ver))
+	completions := []struct {
+		path string
+		dir  string
+		name func(string) string
+	}{
+		{pkgConf.Completions.Bash, filepath.Join("bash-completion", "completions"),
+			func(base string) string { return strings.TrimSuffix(base, ".bash") }},
+		{pkgConf.Completions.Zsh, filepath.Join("zsh", "site-functions"),
+			func(base string) string { return base }},
+		{pkgConf.Completions.Fish, filepath.Join("fish", "vendor_completions.d"),
+			func(base string) string { return base }},
+	}
+	for _, completion := range completions {
+		if completion.path == "" {
+			continue
+		}
+		sharePath := filepath.Join(verDir, filepath.FromSlash(completion.path))
+		linkPath := filepath.Join(utils.WebmanShareDir, completion.dir, completion.name(filepath.Base(sharePath)))
+		sharePaths = append(sharePaths, sharePath)
+		linkPaths = append(linkPaths, linkPath)
+	}
+	for _, manPage := range pkgConf.ManPages.Values {
+		sharePath := filepath.Join(verDir, filepath.FromSlash(manPage))
+		section := manSection(filepath.Base(sharePath))
+		if section == "" {
+			return []string{}, []string{}, fmt.Errorf("unable to tell the man section of %s", manPage)
+		}
+		linkPath := filepath.Join(utils.WebmanShareDir, "man", "man"+section, filepath.Base(sharePath))
+		sharePaths = append(sharePaths, sharePath)
+		linkPaths = append(linkPaths, linkPath)
+	}
+	return sharePaths, linkPaths, nil
+}
+
+// Returns the man section from a man page file name, like "1" for "rg.1" or "rg.1.gz"
+func manSection(name string) string {
+	name = strings.TrimSuffix(name, ".gz")
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "" || ext[0] < '0' || ext[0] > '9' {
+		return ""
+	}
+	return ext[:1]
+}
+
+// Links the completion scripts and man pages of a package version into ~/.webman/share
+func CreateShareLinks(pkg string, ver string, pkgConf *pkgparse.PkgConfig) error {
+	sharePaths, linkPaths, err := GetSharePathsAndLinkPaths(pkg, ver, pkgConf)
+	if err != nil {
+		return err
+	}
+	for i, linkPath := range linkPaths {
+		if _, err := os.Stat(sharePaths[i]); err != nil {
+			return fmt.Errorf("unable to access %s: %v", sharePaths[i], err)
+		}
+		if err := os.MkdirAll(filepath.Dir(linkPath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := addShareLink(sharePaths[i], linkPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the ~/.webman/share links of a package version
+func RemoveShareLinks(pkg string, ver string, pkgConf *pkgparse.PkgConfig) error {
+	_, linkPaths, err := GetSharePathsAndLinkPaths(pkg, ver, pkgConf)
+	if err != nil {
+		return err
+	}
+	for _, linkPath := range linkPaths {
+		if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replaces the share links of the in-use package version (if any) with those of a new version.
+// The new links are renamed over the old ones like bin links, so completions and man pages
+// are never missing, and only then are old links the new version doesn't have removed.
+func ReplaceShareLinks(pkg string, using *string, ver string, pkgConf *pkgparse.PkgConfig) error {
+	if err := CreateShareLinks(pkg, ver, pkgConf); err != nil {
+		return err
+	}
+	if using == nil {
+		return nil
+	}
+	_, usingVer := utils.ParseStem(*using)
+	_, oldLinkPaths, err := GetSharePathsAndLinkPaths(pkg, usingVer, pkgConf)
+	if err != nil {
+		return err
+	}
+	_, newLinkPaths, err := GetSharePathsAndLinkPaths(pkg, ver, pkgConf)
+	if err != nil {
+		return err
+	}
+	replaced := map[string]bool{}
+	for _, linkPath := range newLinkPaths {
+		replaced[linkPath] = true
+	}
+	for _, linkPath := range oldLinkPaths {
+		if replaced[linkPath] {
+			continue
+		}
+		if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Undoes a failed switch from the in-use package version (if any) to a new version,
+// linking the binaries, completions and man pages of the in-use version again
+func RestoreLinks(pkg string, using *string, ver string, binPaths []string, pkgConf *pkgparse.PkgConfig) error {
+	if err := RemoveShareLinks(pkg, ver, pkgConf); err != nil {
+		return err
+	}
+	if using == nil {
+		// nothing was in use, so only drop any bin links made for the new version
+		_, linkPaths, err := GetBinPathsAndLinkPaths(pkg, ver, binPaths)
+		if err != nil {
+			return err
+		}
+		for _, linkPath := range linkPaths {
+			if utils.GOOS == "windows" {
+				linkPath += ".bat"
+			}
+			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	_, usingVer := utils.ParseStem(*using)
+	if err := CreateShareLinks(pkg, usingVer, pkgConf); err != nil {
+		return err
+	}
+	if _, err := CreateLinks(pkg, usingVer, binPaths); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Links a shared file at the new path, replacing any existing link atomically.
+// On windows, the file is copied instead, since symlinks need elevated permissions.
+func addShareLink(old string, new string) error {
+	if utils.GOOS != "windows" {
+		return symlinkAtomic(old, new)
+	}
+	src, err := os.Open(old)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := tempLinkPath(new)
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, new)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package pkgparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode"
+	"webman/utils"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Version aliases keyed by package, then alias name, e.g. go: {work: 1.18.3}.
+// They're kept out of config.yaml so saving them never rewrites the user's settings.
+type aliasFile map[string]map[string]string
+
+func aliasesPath() string {
+	return filepath.Join(utils.WebmanDir, "aliases.yaml")
+}
+
+// Reads aliases.yaml, which doesn't exist until the first alias is saved
+func readAliases() (aliasFile, error) {
+	aliases := aliasFile{}
+	data, err := os.ReadFile(aliasesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, err
+	}
+	if err = yaml.UnmarshalStrict(data, &aliases); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", aliasesPath(), err)
+	}
+	return aliases, nil
+}
+
+func writeAliases(aliases aliasFile) error {
+	data, err := yaml.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(aliasesPath(), data, 0644)
+}
+
+// Returns the user's version aliases for the package
+func (pkgConf *PkgConfig) GetAliases() (map[string]string, error) {
+	aliases, err := readAliases()
+	if err != nil {
+		return nil, err
+	}
+	return aliases[pkgConf.Title], nil
+}
+
+// Saves a version alias for the package in aliases.yaml.
+// The name can't shadow a channel, and the version must be a channel or a version constraint.
+func (pkgConf *PkgConfig) SetAlias(name string, ver string) error {
+	if err := checkSpecName("alias", name); err != nil {
+		return err
+	}
+	if _, exists := pkgConf.GetChannel(name); exists {
+		return fmt.Errorf("%s is already a channel of %s", name, pkgConf.Title)
+	}
+	aliases, err := readAliases()
+	if err != nil {
+		return err
+	}
+	if _, exists := aliases[pkgConf.Title][ver]; exists {
+		return fmt.Errorf("%s is an alias, and aliases can't point to other aliases", ver)
+	}
+	if _, err = pkgConf.parseChannelOrConstraint(ver); err != nil {
+		return err
+	}
+	if aliases[pkgConf.Title] == nil {
+		aliases[pkgConf.Title] = map[string]string{}
+	}
+	aliases[pkgConf.Title][name] = ver
+	return writeAliases(aliases)
+}
+
+// Deletes a version alias for the package from aliases.yaml
+func (pkgConf *PkgConfig) RemoveAlias(name string) error {
+	aliases, err := readAliases()
+	if err != nil {
+		return err
+	}
+	if _, exists := aliases[pkgConf.Title][name]; !exists {
+		return fmt.Errorf("%s has no alias named %s", pkgConf.Title, name)
+	}
+	delete(aliases[pkgConf.Title], name)
+	if len(aliases[pkgConf.Title]) == 0 {
+		delete(aliases, pkgConf.Title)
+	}
+	return writeAliases(aliases)
+}
+
+// Alias and channel names start with a letter so they can't be mistaken for versions,
+// and only use letters, digits, ".", "-" and "_" so they fit in a "pkg@name" argument
+func checkSpecName(kind string, name string) error {
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		return fmt.Errorf("%s name %q must start with a letter", kind, name)
+	}
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '.' && c != '-' && c != '_' {
+			return fmt.Errorf("%s name %q can only contain letters, digits, '.', '-' and '_'", kind, name)
+		}
+	}
+	return nil
+}
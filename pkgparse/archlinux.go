@@ -1,44 +1,97 @@
 package pkgparse
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
-	"net/http"
+	"net/url"
 	"strings"
+	"webman/vercmp"
 )
 
+const archLinuxSearchUrl = "https://archlinux.org/packages/search/json/"
+
+// The Arch repositories searched when a recipe doesn't name one.
+// community has since been merged into extra, but is still searched in case a package is left there.
+var archLinuxRepos = []string{"core", "extra", "community"}
+
 type ArchLinuxPkgInfo struct {
-	PkgVer string
+	PkgName string `json:"pkgname"`
+	Repo    string `json:"repo"`
+	PkgVer  string `json:"pkgver"`
+	PkgRel  string `json:"pkgrel"`
+	Epoch   int    `json:"epoch"`
+}
+
+// Compares two Arch package builds by epoch, then pkgver, then pkgrel
+func compareArchLinuxPkgs(a *ArchLinuxPkgInfo, b *ArchLinuxPkgInfo) int {
+	if a.Epoch != b.Epoch {
+		if a.Epoch < b.Epoch {
+			return -1
+		}
+		return 1
+	}
+	if c := vercmp.Compare(a.PkgVer, b.PkgVer); c != 0 {
+		return c
+	}
+	return vercmp.Compare(a.PkgRel, b.PkgRel)
 }
 
-func getLatestArchLinuxPkgVersion(archpkg string) (*ArchLinuxPkgInfo, error) {
-	url := "https://raw.githubusercontent.com/archlinux/svntogit-community/master/" +
-		archpkg + "/trunk/PKGBUILD"
-	req, err := http.NewRequest("GET", url, nil)
+// Searches for the builds of a package in the given Arch repository,
+// or in core, extra and community if repo is empty
+func listArchLinuxPkgs(archpkg string, repo string) ([]ArchLinuxPkgInfo, error) {
+	repos := archLinuxRepos
+	if repo != "" {
+		repos = []string{repo}
+	}
+	query := url.Values{"name": {archpkg}}
+	for _, r := range repos {
+		query.Add("repo", archLinuxRepoName(r))
+	}
+	body, err := fetchUrl(archLinuxSearchUrl + "?" + query.Encode())
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept-Language", "en-US")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Host", "raw.githubusercontent.com")
-	req.Header.Set("User-Agent", "Mozilla/5.0")
+	var search struct {
+		Results []ArchLinuxPkgInfo
+	}
+	if err = json.Unmarshal(body, &search); err != nil {
+		return nil, fmt.Errorf("archlinux.org package search JSON response not in expected format")
+	}
+	inRepos := map[string]bool{}
+	for _, r := range repos {
+		inRepos[strings.ToLower(r)] = true
+	}
+	var pkgs []ArchLinuxPkgInfo
+	for _, pkg := range search.Results {
+		if pkg.PkgName == archpkg && inRepos[strings.ToLower(pkg.Repo)] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("found no arch linux package named %s in %v", archpkg, repos)
+	}
+	return pkgs, nil
+}
 
-	r, err := http.DefaultClient.Do(req)
+// Finds the newest build of a package, by epoch, pkgver and then pkgrel
+func getLatestArchLinuxPkgVersion(archpkg string, repo string) (*ArchLinuxPkgInfo, error) {
+	pkgs, err := listArchLinuxPkgs(archpkg, repo)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Body.Close()
-	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
-		return nil, fmt.Errorf("Bad HTTP Response: " + r.Status)
-	}
-	scanner := bufio.NewScanner(r.Body)
-	var pkgInfo ArchLinuxPkgInfo
-	for scanner.Scan() {
-		if strings.HasPrefix(scanner.Text(), "pkgver=") {
-			pkgInfo.PkgVer = scanner.Text()[7:]
-			return &pkgInfo, nil
+	latest := &pkgs[0]
+	for i := range pkgs[1:] {
+		if compareArchLinuxPkgs(&pkgs[i+1], latest) > 0 {
+			latest = &pkgs[i+1]
 		}
 	}
+	return latest, nil
+}
 
-	return nil, fmt.Errorf("invalid PKGBUILD file with no pkgver field")
+// The search API takes capitalized repository names, like "Extra"
+func archLinuxRepoName(repo string) string {
+	if repo == "" {
+		return repo
+	}
+	return strings.ToUpper(repo[:1]) + strings.ToLower(repo[1:])
 }
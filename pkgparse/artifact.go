@@ -0,0 +1,88 @@
+package pkgparse
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// An additional download installed into the same version directory as the package,
+// like shell completions, man pages or a companion binary
+type ArtifactConfig struct {
+	Name        string `yaml:"name"`
+	Url         string `yaml:"url"`
+	Ext         string `yaml:"ext"`
+	Sha256      string `yaml:"sha256"`
+	ChecksumUrl string `yaml:"checksum_url"`
+	Dest        string `yaml:"dest"`
+	IsBinary    bool   `yaml:"is_binary"`
+}
+
+// An extra artifact with its templates rendered for one version and platform
+type Artifact struct {
+	Name      string
+	Url       string
+	Ext       string
+	Dest      string
+	IsBinary  bool
+	Checksums []Checksum
+}
+
+// Resolves the extra artifacts for the given version on the current platform,
+// including the checksums they should be verified against
+func (pkgConf *PkgConfig) GetArtifacts(version string) ([]Artifact, error) {
+	if len(pkgConf.ExtraArtifacts) == 0 {
+		return nil, nil
+	}
+	plat, err := pkgConf.GetMyPlatform()
+	if err != nil {
+		return nil, err
+	}
+	vars := pkgConf.templateVars(version, plat)
+	artifacts := make([]Artifact, len(pkgConf.ExtraArtifacts))
+	for i, artifactConf := range pkgConf.ExtraArtifacts {
+		url, err := renderTemplate(artifactConf.Url, vars)
+		if err != nil {
+			return nil, err
+		}
+		artifact := Artifact{
+			Name:     artifactConf.Name,
+			Url:      url,
+			Ext:      artifactConf.Ext,
+			Dest:     artifactConf.Dest,
+			IsBinary: artifactConf.IsBinary,
+		}
+		if artifact.Name == "" {
+			artifact.Name = path.Base(url)
+			if artifact.Ext != "" {
+				artifact.Name = strings.TrimSuffix(artifact.Name, "."+artifact.Ext)
+			}
+		}
+		if artifactConf.Sha256 != "" {
+			artifact.Checksums = append(artifact.Checksums, Checksum{Source: "sha256", Sha256: artifactConf.Sha256})
+		}
+		if artifactConf.ChecksumUrl != "" {
+			sumUrl, err := renderTemplate(artifactConf.ChecksumUrl, vars)
+			if err != nil {
+				return nil, err
+			}
+			data, err := fetchUrl(sumUrl)
+			if err != nil {
+				return nil, fmt.Errorf("unable to download checksum file: %v", err)
+			}
+			sum, err := findChecksum(data, path.Base(url))
+			if err != nil {
+				return nil, fmt.Errorf("%v in %s", err, sumUrl)
+			}
+			artifact.Checksums = append(artifact.Checksums, Checksum{Source: "checksum_url", Sha256: sum})
+		}
+		for j, sum := range artifact.Checksums {
+			if !IsSha256(sum.Sha256) {
+				return nil, fmt.Errorf("%s checksum %q for %s is not a SHA-256 digest", sum.Source, sum.Sha256, artifact.Name)
+			}
+			artifact.Checksums[j].Sha256 = strings.ToLower(sum.Sha256)
+		}
+		artifacts[i] = artifact
+	}
+	return artifacts, nil
+}
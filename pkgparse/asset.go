@@ -0,0 +1,76 @@
+package pkgparse
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Returns whether the recipe picks its download from the GitHub release asset listing
+func (pkgConf *PkgConfig) UsesAssetPattern() bool {
+	return pkgConf.AssetPattern != "" || pkgConf.AssetRegex != ""
+}
+
+// Returns a function matching asset names against the recipe's asset_pattern glob or asset_regex,
+// with the recipe templates rendered for the given version and platform.
+// Bracket placeholders in asset_regex are regex-quoted.
+func (pkgConf *PkgConfig) assetMatcher(version string, plat *Platform) (func(string) bool, string, error) {
+	vars := pkgConf.templateVars(version, plat)
+	if pkgConf.AssetRegex != "" {
+		expr, err := renderQuotedTemplate(pkgConf.AssetRegex, vars, regexp.QuoteMeta)
+		if err != nil {
+			return nil, "", err
+		}
+		exp, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to compile asset_regex: %v", err)
+		}
+		return exp.MatchString, expr, nil
+	}
+	pattern, err := renderTemplate(pkgConf.AssetPattern, vars)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, "", fmt.Errorf("invalid asset_pattern: %v", err)
+	}
+	return func(name string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
+	}, pattern, nil
+}
+
+// Selects the single GitHub release asset matching the recipe's pattern for this platform
+func (pkgConf *PkgConfig) findReleaseAsset(version string, plat *Platform) (*AssetInfo, error) {
+	matches, pattern, err := pkgConf.assetMatcher(version, plat)
+	if err != nil {
+		return nil, err
+	}
+	release, err := getGithubReleaseByVersion(pkgConf.GitUser, pkgConf.GitRepo, version, pkgConf.VersionFormat)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []string
+	var matched []AssetInfo
+	for _, asset := range release.Assets {
+		candidates = append(candidates, asset.Name)
+		if matches(asset.Name) {
+			matched = append(matched, asset)
+		}
+	}
+	switch len(matched) {
+	case 1:
+		return &matched[0], nil
+	case 0:
+		return nil, fmt.Errorf("no release asset of %s matches %q, candidates are: %s",
+			release.TagName, pattern, strings.Join(candidates, ", "))
+	default:
+		var names []string
+		for _, asset := range matched {
+			names = append(names, asset.Name)
+		}
+		return nil, fmt.Errorf("%d release assets of %s match %q: %s",
+			len(matched), release.TagName, pattern, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,212 @@
+package pkgparse
+
+import (
+	"fmt"
+	"webman/vercmp"
+)
+
+// A named release channel from a recipe, like "lts", selecting the newest version that passes its filters
+type Channel struct {
+	AllowPrerelease bool   `yaml:"allow_prerelease"`
+	Lts             bool   `yaml:"lts"`
+	Constraint      string `yaml:"constraint"`
+}
+
+// Channels every recipe has unless it defines its own with the same name
+var BuiltinChannels = []string{"latest", "stable", "next"}
+
+// Returns the recipe channel with the given name, or the builtin one.
+// "latest" follows the recipe's allow_prerelease and lts_only settings,
+// "stable" never includes prereleases, and "next" always does.
+func (pkgConf *PkgConfig) GetChannel(name string) (*Channel, bool) {
+	if ch, exists := pkgConf.Channels[name]; exists {
+		return &ch, true
+	}
+	switch name {
+	case "latest":
+		return &Channel{AllowPrerelease: pkgConf.AllowPrerelease, Lts: pkgConf.LtsOnly}, true
+	case "stable":
+		return &Channel{Lts: pkgConf.LtsOnly}, true
+	case "next":
+		return &Channel{AllowPrerelease: true}, true
+	}
+	return nil, false
+}
+
+// Checks that the recipe's channel names are usable in "pkg@channel" arguments,
+// their constraints parse, and lts is only used with a strategy that knows LTS releases
+func (pkgConf *PkgConfig) CheckChannels() error {
+	for name, ch := range pkgConf.Channels {
+		if err := checkSpecName("channel", name); err != nil {
+			return err
+		}
+		if ch.Constraint != "" {
+			if _, err := vercmp.ParseConstraint(ch.Constraint); err != nil {
+				return fmt.Errorf("channel %s: %v", name, err)
+			}
+		}
+		if ch.Lts && pkgConf.LatestStrategy != "node-index" {
+			return fmt.Errorf("channel %s: lts is only supported by the node-index latest strategy", name)
+		}
+	}
+	return nil
+}
+
+// A version spec from a "pkg@spec" argument, after expanding user aliases and channels
+type VersionSpec struct {
+	// The spec as written, or "latest" if none was given
+	Spec string
+	// Set if the spec names a channel
+	Channel string
+	// A nil constraint matches any version
+	Constraint      *vercmp.Constraint
+	AllowPrerelease bool
+	Lts             bool
+}
+
+// Parses a version spec, which is either a user alias from aliases.yaml,
+// a channel name, or a version constraint like "1.18" or "^16".
+// An empty spec means the latest channel.
+// Aliases may point to a channel or a constraint, but not to another alias.
+func (pkgConf *PkgConfig) ParseVersionSpec(spec string) (*VersionSpec, error) {
+	if spec == "" {
+		spec = "latest"
+	}
+	target := spec
+	aliases, err := pkgConf.GetAliases()
+	if err != nil {
+		return nil, err
+	}
+	if aliased, exists := aliases[spec]; exists {
+		target = aliased
+	}
+	vs, err := pkgConf.parseChannelOrConstraint(target)
+	if err != nil {
+		if target != spec {
+			return nil, fmt.Errorf("alias %s for %s: %v", spec, pkgConf.Title, err)
+		}
+		return nil, err
+	}
+	vs.Spec = spec
+	return vs, nil
+}
+
+func (pkgConf *PkgConfig) parseChannelOrConstraint(spec string) (*VersionSpec, error) {
+	vs := VersionSpec{Spec: spec}
+	if ch, exists := pkgConf.GetChannel(spec); exists {
+		vs.Channel = spec
+		vs.AllowPrerelease = ch.AllowPrerelease
+		vs.Lts = ch.Lts
+		if ch.Constraint != "" {
+			c, err := vercmp.ParseConstraint(ch.Constraint)
+			if err != nil {
+				return nil, fmt.Errorf("channel %s: %v", spec, err)
+			}
+			vs.Constraint = c
+			vs.AllowPrerelease = vs.AllowPrerelease || c.HasPrerelease()
+		}
+		return &vs, nil
+	}
+	c, err := vercmp.ParseConstraint(spec)
+	if err != nil {
+		return nil, err
+	}
+	vs.Constraint = c
+	vs.AllowPrerelease = pkgConf.AllowPrerelease || c.HasPrerelease()
+	return &vs, nil
+}
+
+// Returns whether the spec is the builtin latest channel,
+// which is found with the recipe's latest strategy rather than by listing every version
+func (vs *VersionSpec) IsLatest() bool {
+	return vs.Channel == "latest" && vs.Constraint == nil
+}
+
+// Returns the version if the spec only matches one exact version
+func (vs *VersionSpec) Exact() (string, bool) {
+	if vs.Channel != "" || vs.Constraint == nil {
+		return "", false
+	}
+	return vs.Constraint.Exact()
+}
+
+// Returns whether the version passes the spec's constraint and filters
+func (vs *VersionSpec) Check(ver RemoteVersion) bool {
+	if ver.Prerelease && !vs.AllowPrerelease {
+		return false
+	}
+	if vs.Lts && !ver.Lts {
+		return false
+	}
+	return vs.Constraint == nil || vs.Constraint.Check(ver.Version)
+}
+
+// Finds the newest remote version matching the spec
+func (pkgConf *PkgConfig) ResolveSpec(vs *VersionSpec) (*string, error) {
+	if ver, isExact := vs.Exact(); isExact {
+		return &ver, nil
+	}
+	if vs.IsLatest() {
+		return pkgConf.GetLatestVersion()
+	}
+	vers, err := pkgConf.ListVersions()
+	if err != nil {
+		return nil, err
+	}
+	for _, ver := range vers {
+		if vs.Check(ver) {
+			return &ver.Version, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s version matches %s", pkgConf.Title, vs.Spec)
+}
+
+// Returns whether a remote version of the package matches the spec,
+// looking up whether it's an LTS release only if the spec needs to know
+func (pkgConf *PkgConfig) CheckVersion(vs *VersionSpec, ver string) (bool, error) {
+	remoteVer := RemoteVersion{Version: ver, Prerelease: vercmp.IsPrerelease(ver)}
+	if vs.Lts {
+		vers, err := pkgConf.ListVersions()
+		if err != nil {
+			return false, err
+		}
+		for _, v := range vers {
+			if v.Version == ver {
+				remoteVer = v
+				break
+			}
+		}
+	}
+	return vs.Check(remoteVer), nil
+}
+
+// Finds the newest installed version of the package matching the version spec.
+// Installed prereleases are skipped unless the spec allows them, like a spec naming one or a recipe with allow_prerelease.
+// Channels that only take LTS releases look up which versions are LTS remotely,
+// and treat every installed version as LTS if the lookup fails, like when offline.
+func (pkgConf *PkgConfig) ResolveInstalledVersion(spec string) (*string, error) {
+	vs, err := pkgConf.ParseVersionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	installed, err := InstalledVersions(pkgConf.Title)
+	if err != nil {
+		return nil, err
+	}
+	var lts map[string]bool
+	if vs.Lts {
+		if remoteVers, err := pkgConf.ListVersions(); err == nil {
+			lts = map[string]bool{}
+			for _, ver := range remoteVers {
+				lts[ver.Version] = ver.Lts
+			}
+		}
+	}
+	// installed versions are sorted newest first
+	for _, ver := range installed {
+		if vs.Check(RemoteVersion{Version: ver, Prerelease: vercmp.IsPrerelease(ver), Lts: lts == nil || lts[ver]}) {
+			return &ver, nil
+		}
+	}
+	return nil, fmt.Errorf("no installed %s version matches %s", pkgConf.Title, vs.Spec)
+}
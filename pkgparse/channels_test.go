@@ -0,0 +1,48 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"webman/utils"
+)
+
+func TestResolveInstalledVersion(t *testing.T) {
+	useTempWebmanDir(t)
+	for _, ver := range []string{"1.18.2", "1.19rc1", "1.19.0-rc2", "1.20.1", "2.0.0-beta.1"} {
+		if err := os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "go", utils.CreateStem("go", ver)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		spec            string
+		allowPrerelease bool
+		want            string
+	}{
+		{"1.18", false, "1.18.2"},
+		{"1.19", false, ""},
+		{"1.19rc1", false, "1.19rc1"},
+		{">=1.19.0-rc1 <1.20", false, "1.19.0-rc2"},
+		{"^1", false, "1.20.1"},
+		{"^1.18", false, "1.20.1"},
+		{"latest", false, "1.20.1"},
+		{"next", false, "2.0.0-beta.1"},
+		{"1.18", true, "1.18.2"},
+		{"1.19", true, ""},
+		{"<1.20", false, "1.18.2"},
+		{"<1.20", true, "1.19.0-rc2"},
+		{">=2.0.0-alpha", false, "2.0.0-beta.1"},
+	}
+	for _, tt := range tests {
+		pkgConf := &PkgConfig{Title: "go", AllowPrerelease: tt.allowPrerelease}
+		ver, err := pkgConf.ResolveInstalledVersion(tt.spec)
+		got := ""
+		if err == nil {
+			got = *ver
+		}
+		if got != tt.want {
+			t.Errorf("ResolveInstalledVersion(%q) with allow_prerelease %v = %q (%v), want %q",
+				tt.spec, tt.allowPrerelease, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package pkgparse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// An expected SHA-256 digest for a download, along with the recipe field it came from
+type Checksum struct {
+	Source string
+	Sha256 string
+}
+
+// Returns whether the recipe declares any checksum source
+func (pkgConf *PkgConfig) HasChecksum() bool {
+	return len(pkgConf.Sha256) != 0 || pkgConf.ChecksumUrl != "" || pkgConf.GithubDigest ||
+		pkgConf.UsesVendorDownloads()
+}
+
+// Resolves the expected digest from every checksum source declared by the recipe
+// for the download at the given URL.
+func (pkgConf *PkgConfig) GetChecksums(version string, url string) ([]Checksum, error) {
+	fileName := path.Base(url)
+	var sums []Checksum
+	if len(pkgConf.Sha256) != 0 {
+		key := PlatformKey()
+		sum, exists := pkgConf.Sha256[version][key]
+		if !exists {
+			return nil, fmt.Errorf("recipe has no sha256 for version %s on %s", version, key)
+		}
+		sums = append(sums, Checksum{Source: "sha256", Sha256: sum})
+	}
+	if pkgConf.ChecksumUrl != "" {
+		plat, err := pkgConf.GetMyPlatform()
+		if err != nil {
+			return nil, err
+		}
+		sumUrl, err := renderTemplate(pkgConf.ChecksumUrl, pkgConf.templateVars(version, plat))
+		if err != nil {
+			return nil, err
+		}
+		data, err := fetchUrl(sumUrl)
+		if err != nil {
+			return nil, fmt.Errorf("unable to download checksum file: %v", err)
+		}
+		sum, err := findChecksum(data, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("%v in %s", err, sumUrl)
+		}
+		sums = append(sums, Checksum{Source: "checksum_url", Sha256: sum})
+	}
+	if pkgConf.GithubDigest {
+		tag, found := githubDownloadTag(url)
+		if !found {
+			tag = pkgConf.TagForVersion(version)
+		}
+		sum, err := getGithubAssetDigest(pkgConf.GitUser, pkgConf.GitRepo, tag, url)
+		if err != nil {
+			return nil, err
+		}
+		sums = append(sums, Checksum{Source: "github_digest", Sha256: *sum})
+	}
+	if pkgConf.UsesVendorDownloads() {
+		sum, err := pkgConf.getVendorChecksum(version, url)
+		if err != nil {
+			return nil, err
+		}
+		sums = append(sums, Checksum{Source: pkgConf.LatestStrategy, Sha256: sum})
+	}
+	for i, sum := range sums {
+		if !IsSha256(sum.Sha256) {
+			return nil, fmt.Errorf("%s checksum %q is not a SHA-256 digest", sum.Source, sum.Sha256)
+		}
+		sums[i].Sha256 = strings.ToLower(sum.Sha256)
+	}
+	return sums, nil
+}
+
+// Returns whether the string is a hex-encoded SHA-256 digest
+func IsSha256(sum string) bool {
+	if len(sum) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(sum)
+	return err == nil
+}
+
+// Finds the digest for a file in a checksum file.
+// This understands sha256sum output ("<digest>  <file>" or "<digest> *<file>"),
+// BSD-style output ("SHA256 (<file>) = <digest>"), and files holding a single digest.
+func findChecksum(data []byte, fileName string) (string, error) {
+	var lines [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "SHA256 (") {
+			name, sum, found := strings.Cut(strings.TrimPrefix(line, "SHA256 ("), ") = ")
+			if found && path.Base(name) == fileName {
+				return strings.TrimSpace(sum), nil
+			}
+			continue
+		}
+		lines = append(lines, strings.Fields(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if len(lines) == 1 && len(lines[0]) == 1 {
+		return lines[0][0], nil
+	}
+	for _, fields := range lines {
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[len(fields)-1], "*")
+		if path.Base(name) == fileName {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s", fileName)
+}
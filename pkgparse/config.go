@@ -0,0 +1,55 @@
+package pkgparse
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"webman/utils"
+
+	"github.com/go-yaml/yaml"
+)
+
+// User settings stored in ~/.webman/config.yaml
+type WebmanConfig struct {
+	// API tokens keyed by host, e.g. "gitlab.com" or "git.example.com"
+	Tokens map[string]string `yaml:"tokens,omitempty"`
+}
+
+func configPath() string {
+	return filepath.Join(utils.WebmanDir, "config.yaml")
+}
+
+// Reads the user config.
+// If config.yaml doesn't exist, every setting is left empty.
+func ParseWebmanConfig() (*WebmanConfig, error) {
+	var conf WebmanConfig
+	data, err := os.ReadFile(configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &conf, nil
+		}
+		return nil, err
+	}
+	if err = yaml.UnmarshalStrict(data, &conf); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", configPath(), err)
+	}
+	return &conf, nil
+}
+
+// Returns the configured token for the host of the given URL,
+// falling back to the environment variable if the config has none
+func GetToken(hostUrl string, envVar string) (string, error) {
+	conf, err := ParseWebmanConfig()
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(hostUrl)
+	if err != nil {
+		return "", err
+	}
+	if token, exists := conf.Tokens[u.Host]; exists {
+		return token, nil
+	}
+	return os.Getenv(envVar), nil
+}
@@ -0,0 +1,114 @@
+package pkgparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"webman/utils"
+	"webman/vercmp"
+)
+
+// A package another package needs at runtime, with an optional version constraint
+type Dependency struct {
+	Pkg        string
+	Constraint *vercmp.Constraint
+}
+
+func (dep *Dependency) String() string {
+	if dep.Constraint == nil {
+		return dep.Pkg
+	}
+	return dep.Pkg + " (" + dep.Constraint.String() + ")"
+}
+
+// Returns whether the version satisfies the dependency's constraint, if it has one
+func (dep *Dependency) Check(ver string) bool {
+	return dep.Constraint == nil || dep.Constraint.Check(ver)
+}
+
+// Parses the recipe's depends list, where each entry is 'pkg' or 'pkg@constraint'
+func (pkgConf *PkgConfig) GetDependencies() ([]Dependency, error) {
+	deps := make([]Dependency, len(pkgConf.Depends))
+	for i, entry := range pkgConf.Depends {
+		pkg, spec, err := utils.ParsePkgVer(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependency %q: %v", entry, err)
+		}
+		deps[i].Pkg = pkg
+		if spec != "" {
+			if deps[i].Constraint, err = vercmp.ParseConstraint(spec); err != nil {
+				return nil, fmt.Errorf("invalid dependency %q: %v", entry, err)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// Returns the installed versions of a package, newest first
+func InstalledVersions(pkg string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(utils.WebmanPkgDir, pkg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var vers []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), pkg+"-") {
+			continue
+		}
+		vers = append(vers, strings.TrimPrefix(entry.Name(), pkg+"-"))
+	}
+	vercmp.SortDesc(vers)
+	return vers, nil
+}
+
+// Returns whether an installed version of the package satisfies the dependency
+func (dep *Dependency) IsInstalled() (bool, error) {
+	vers, err := InstalledVersions(dep.Pkg)
+	if err != nil {
+		return false, err
+	}
+	for _, ver := range vers {
+		if dep.Check(ver) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// An installed package depending on another package
+type Dependent struct {
+	Pkg string
+	Dep Dependency
+}
+
+// Finds the installed packages whose recipes depend on the given package
+func FindDependents(pkg string) ([]Dependent, error) {
+	entries, err := os.ReadDir(utils.WebmanPkgDir)
+	if err != nil {
+		return nil, err
+	}
+	var dependents []Dependent
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == pkg {
+			continue
+		}
+		pkgConf, err := ParsePkgConfigLocal(entry.Name(), false)
+		if err != nil {
+			continue
+		}
+		deps, err := pkgConf.GetDependencies()
+		if err != nil {
+			continue
+		}
+		for _, dep := range deps {
+			if dep.Pkg == pkg {
+				dependents = append(dependents, Dependent{Pkg: entry.Name(), Dep: dep})
+			}
+		}
+	}
+	return dependents, nil
+}
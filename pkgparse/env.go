@@ -0,0 +1,42 @@
+package pkgparse
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"webman/utils"
+)
+
+// Environment variable names are printed unquoted into shell commands by "webman env",
+// so they're limited to the characters every shell accepts in a name
+var envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Returns an error if the recipe declares an environment variable with an invalid name
+func (pkgConf *PkgConfig) CheckEnv() error {
+	for key := range pkgConf.Env {
+		if !envKeyRegex.MatchString(key) {
+			return fmt.Errorf("env: %q is not a valid environment variable name", key)
+		}
+	}
+	return nil
+}
+
+// Returns the environment variables declared by the recipe for an installed version,
+// as "KEY=value" pairs sorted by key.
+// [PKG_DIR] is replaced with the version directory, and [VER] with the version.
+func (pkgConf *PkgConfig) GetEnv(ver string) ([]string, error) {
+	if err := pkgConf.CheckEnv(); err != nil {
+		return nil, err
+	}
+	verDir := filepath.Join(utils.WebmanPkgDir, pkgConf.Title, utils.CreateStem(pkgConf.Title, ver))
+	env := make([]string, 0, len(pkgConf.Env))
+	for key, val := range pkgConf.Env {
+		val = strings.ReplaceAll(val, "[PKG_DIR]", verDir)
+		val = strings.ReplaceAll(val, "[VER]", ver)
+		env = append(env, key+"="+val)
+	}
+	sort.Strings(env)
+	return env, nil
+}
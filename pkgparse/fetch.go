@@ -0,0 +1,32 @@
+package pkgparse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Downloads the body of a small file, like a checksum list or an API response
+func fetchUrl(url string) ([]byte, error) {
+	return fetchUrlWithHeaders(url, nil)
+}
+
+// Like fetchUrl, but sets the given request headers, e.g. for API tokens
+func fetchUrlWithHeaders(url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
+		return nil, fmt.Errorf("bad HTTP response from %s: %s", url, r.Status)
+	}
+	return ioutil.ReadAll(r.Body)
+}
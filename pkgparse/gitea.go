@@ -0,0 +1,75 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultGiteaHost = "https://codeberg.org"
+
+// Returns the Gitea or Forgejo instance URL, defaulting to Codeberg
+func (pkgConf *PkgConfig) GetGiteaHost() string {
+	if pkgConf.GiteaHost == "" {
+		return defaultGiteaHost
+	}
+	return strings.TrimSuffix(pkgConf.GiteaHost, "/")
+}
+
+// Pages through a Gitea or Forgejo repository's releases, newest first, until visit returns true
+func visitGiteaReleases(host string, user string, repo string, visit func(releases []ReleaseTagInfo) bool) error {
+	token, err := GetToken(host, "GITEA_TOKEN")
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "token " + token
+	}
+	for page := 1; page <= 10; page++ {
+		url := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=50&page=%d", host, user, repo, page)
+		body, err := fetchUrlWithHeaders(url, headers)
+		if err != nil {
+			return err
+		}
+		var releases []ReleaseTagInfo
+		if err = json.Unmarshal(body, &releases); err != nil {
+			return fmt.Errorf("gitea releases JSON response not in expected format")
+		}
+		if len(releases) == 0 || visit(releases) {
+			break
+		}
+	}
+	return nil
+}
+
+func listGiteaReleases(host string, user string, repo string) ([]ReleaseTagInfo, error) {
+	var all []ReleaseTagInfo
+	err := visitGiteaReleases(host, user, repo, func(releases []ReleaseTagInfo) bool {
+		all = append(all, releases...)
+		return false
+	})
+	return all, err
+}
+
+// Finds the newest release of a Gitea or Forgejo repository, skipping drafts
+// and skipping prereleases unless allowPrerelease is set
+func getLatestGiteaReleaseTag(host string, user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
+	var latest *ReleaseTagInfo
+	err := visitGiteaReleases(host, user, repo, func(releases []ReleaseTagInfo) bool {
+		for i, release := range releases {
+			if (allowPrerelease || !release.Prerelease) && !release.Draft {
+				latest = &releases[i]
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("found no stable releases for %s/%s on %s", user, repo, host)
+	}
+	return latest, nil
+}
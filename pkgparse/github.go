@@ -6,8 +6,11 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 	"webman/unpack"
 	"webman/utils"
@@ -26,6 +29,7 @@ type AssetInfo struct {
 	Name               string
 	Size               uint32
 	BrowserDownloadUrl string `json:"browser_download_url"`
+	Digest             string
 }
 
 type ReleaseTagInfo struct {
@@ -35,17 +39,59 @@ type ReleaseTagInfo struct {
 	Draft      bool
 }
 
-func getLatestGithubReleaseTag(user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", user, repo)
-	r, err := http.Get(url)
+// Returned when GitHub refuses an API request because the rate limit is used up
+type GithubRateLimitError struct {
+	Reset time.Time
+}
+
+func (e *GithubRateLimitError) Error() string {
+	msg := "github API rate limit exceeded"
+	if !e.Reset.IsZero() {
+		msg += fmt.Sprintf(" until %s", e.Reset.Local().Format("15:04"))
+	}
+	return msg + ", set GITHUB_TOKEN or add a github.com token to ~/.webman/config.yaml to raise the limit"
+}
+
+// Calls the GitHub REST API, authenticating with a configured token if there is one
+func githubApiGet(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	token, err := GetToken("https://github.com", "GITHUB_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer r.Body.Close()
+	// secondary rate limits answer with a Retry-After header instead of X-RateLimit-Remaining
+	retryAfter := r.Header.Get("Retry-After")
+	if r.StatusCode == http.StatusTooManyRequests ||
+		(r.StatusCode == http.StatusForbidden && (r.Header.Get("X-RateLimit-Remaining") == "0" || retryAfter != "")) {
+		var rateErr GithubRateLimitError
+		if secs, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+			rateErr.Reset = time.Now().Add(time.Duration(secs) * time.Second)
+		} else if reset, err := strconv.ParseInt(r.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			rateErr.Reset = time.Unix(reset, 0)
+		}
+		return nil, &rateErr
+	}
 	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
-		return nil, fmt.Errorf("bad HTTP Response: %s", r.Status)
+		return nil, fmt.Errorf("bad HTTP response from %s: %s", url, r.Status)
 	}
-	body, err := ioutil.ReadAll(r.Body)
+	return ioutil.ReadAll(r.Body)
+}
+
+func getLatestGithubReleaseTag(user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", user, repo)
+	body, err := githubApiGet(url)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +110,161 @@ func getLatestGithubReleaseTag(user string, repo string, allowPrerelease bool) (
 	return nil, fmt.Errorf("found no stable releases for %s/%s", user, repo)
 }
 
+// Reads the latest release tag from where github.com/{user}/{repo}/releases/latest redirects to.
+// This doesn't use the API, so it isn't rate limited, but it only finds the latest stable release.
+func getLatestGithubRedirectTag(user string, repo string) (*string, error) {
+	latestUrl := fmt.Sprintf("https://github.com/%s/%s/releases/latest", user, repo)
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	r, err := client.Get(latestUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if !(r.StatusCode >= 300 && r.StatusCode < 400) {
+		return nil, fmt.Errorf("expected a redirect from %s, got %s", latestUrl, r.Status)
+	}
+	_, tag, found := strings.Cut(r.Header.Get("Location"), "/releases/tag/")
+	if !found || tag == "" {
+		return nil, fmt.Errorf("found no latest release for %s/%s", user, repo)
+	}
+	tag, err = neturl.PathUnescape(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+type TagInfo struct {
+	Name string
+}
+
+// Pages through the names of a repository's GitHub tags
+func listGithubTags(user string, repo string) ([]string, error) {
+	var names []string
+	for page := 1; page <= 10; page++ {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/tags?per_page=100&page=%d", user, repo, page)
+		body, err := githubApiGet(url)
+		if err != nil {
+			return nil, err
+		}
+		var tags []TagInfo
+		if err = json.Unmarshal(body, &tags); err != nil {
+			return nil, fmt.Errorf("github tags JSON response not in expected format")
+		}
+		if len(tags) == 0 {
+			break
+		}
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+	}
+	return names, nil
+}
+
+// Returns the GitHub tag with the highest version matching versionFmt,
+// since the API orders tags by name rather than by version
+func getLatestGithubTag(user string, repo string, versionFmt string, allowPrerelease bool) (*string, error) {
+	names, err := listGithubTags(user, repo)
+	if err != nil {
+		return nil, err
+	}
+	tag, found := latestTag(names, versionFmt, allowPrerelease)
+	if !found {
+		return nil, fmt.Errorf("found no github tags for %s/%s matching the version format", user, repo)
+	}
+	return &tag, nil
+}
+
+// Pages through a repository's GitHub releases, newest first
+func listGithubReleaseTags(user string, repo string) ([]ReleaseTagInfo, error) {
+	var all []ReleaseTagInfo
+	for page := 1; page <= 10; page++ {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=100&page=%d", user, repo, page)
+		body, err := githubApiGet(url)
+		if err != nil {
+			return nil, err
+		}
+		var releases []ReleaseTagInfo
+		if err = json.Unmarshal(body, &releases); err != nil {
+			return nil, fmt.Errorf("github releases JSON response not in expected format")
+		}
+		if len(releases) == 0 {
+			break
+		}
+		all = append(all, releases...)
+	}
+	return all, nil
+}
+
+// Pages through the GitHub releases to find the one whose tag parses to the given version
+func getGithubReleaseByVersion(user string, repo string, version string, versionFmt string) (*ReleaseInfo, error) {
+	for page := 1; page <= 10; page++ {
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=100&page=%d", user, repo, page)
+		body, err := githubApiGet(url)
+		if err != nil {
+			return nil, err
+		}
+		var releases []ReleaseInfo
+		if err = json.Unmarshal(body, &releases); err != nil {
+			return nil, fmt.Errorf("github releases JSON response not in expected format")
+		}
+		if len(releases) == 0 {
+			break
+		}
+		for _, release := range releases {
+			relVer, err := ParseVersion(release.TagName, versionFmt)
+			if err == nil && *relVer == version {
+				return &release, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("found no github release for version %s of %s/%s", version, user, repo)
+}
+
+// Finds the digest GitHub published for the release asset at the given download URL,
+// looking up the release with the given tag
+func getGithubAssetDigest(user string, repo string, tag string, downloadUrl string) (*string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", user, repo, neturl.PathEscape(tag))
+	body, err := githubApiGet(url)
+	if err != nil {
+		return nil, err
+	}
+	var release ReleaseInfo
+	if err = json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("github release JSON response not in expected format")
+	}
+	for _, asset := range release.Assets {
+		if asset.BrowserDownloadUrl != downloadUrl {
+			continue
+		}
+		algo, digest, found := strings.Cut(asset.Digest, ":")
+		if !found || algo != "sha256" {
+			return nil, fmt.Errorf("github has not published a sha256 digest for %s", asset.Name)
+		}
+		return &digest, nil
+	}
+	return nil, fmt.Errorf("no asset of github release %s found for %s", tag, downloadUrl)
+}
+
+// Returns the release tag in a GitHub download URL,
+// like v1.2.3 for https://github.com/user/repo/releases/download/v1.2.3/tool.tar.gz
+func githubDownloadTag(downloadUrl string) (string, bool) {
+	_, rest, found := strings.Cut(downloadUrl, "/releases/download/")
+	if !found {
+		return "", false
+	}
+	tag, _, found := strings.Cut(rest, "/")
+	if !found || tag == "" {
+		return "", false
+	}
+	tag, err := neturl.PathUnescape(tag)
+	return tag, err == nil
+}
+
 type GithubDir struct {
 	Name        string
 	DownloadUrl string `yaml:"download_url"`
@@ -0,0 +1,99 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"webman/vercmp"
+)
+
+const defaultGitlabHost = "https://gitlab.com"
+
+type GitlabReleaseInfo struct {
+	TagName         string `json:"tag_name"`
+	Date            string `json:"released_at"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+}
+
+// Returns the GitLab instance URL, defaulting to gitlab.com
+func (pkgConf *PkgConfig) GetGitlabHost() string {
+	if pkgConf.GitlabHost == "" {
+		return defaultGitlabHost
+	}
+	return strings.TrimSuffix(pkgConf.GitlabHost, "/")
+}
+
+// Returns the GitLab project path, defaulting to git_user/git_repo
+func (pkgConf *PkgConfig) GetGitlabProject() string {
+	if pkgConf.GitlabProject == "" {
+		return pkgConf.GitUser + "/" + pkgConf.GitRepo
+	}
+	return strings.Trim(pkgConf.GitlabProject, "/")
+}
+
+// Pages through a GitLab project's releases, newest first, until visit returns true
+func visitGitlabReleases(host string, project string, visit func(releases []GitlabReleaseInfo) bool) error {
+	token, err := GetToken(host, "GITLAB_TOKEN")
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	if token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+	for page := 1; page <= 10; page++ {
+		apiUrl := fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=100&page=%d",
+			host, url.PathEscape(project), page)
+		body, err := fetchUrlWithHeaders(apiUrl, headers)
+		if err != nil {
+			return err
+		}
+		var releases []GitlabReleaseInfo
+		if err = json.Unmarshal(body, &releases); err != nil {
+			return fmt.Errorf("gitlab releases JSON response not in expected format")
+		}
+		if len(releases) == 0 || visit(releases) {
+			break
+		}
+	}
+	return nil
+}
+
+func listGitlabReleases(host string, project string) ([]GitlabReleaseInfo, error) {
+	var all []GitlabReleaseInfo
+	err := visitGitlabReleases(host, project, func(releases []GitlabReleaseInfo) bool {
+		all = append(all, releases...)
+		return false
+	})
+	return all, err
+}
+
+// Finds the newest release of a GitLab project.
+// Upcoming releases and prerelease versions are skipped unless allowPrerelease is set.
+func getLatestGitlabReleaseTag(host string, project string, versionFmt string, allowPrerelease bool) (*GitlabReleaseInfo, error) {
+	var latest *GitlabReleaseInfo
+	err := visitGitlabReleases(host, project, func(releases []GitlabReleaseInfo) bool {
+		for i, release := range releases {
+			if !allowPrerelease {
+				if release.UpcomingRelease {
+					continue
+				}
+				ver, err := ParseVersion(release.TagName, versionFmt)
+				if err != nil || vercmp.IsPrerelease(*ver) {
+					continue
+				}
+			}
+			latest = &releases[i]
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("found no stable releases for %s on %s", project, host)
+	}
+	return latest, nil
+}
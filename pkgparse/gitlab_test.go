@@ -0,0 +1,124 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"webman/utils"
+)
+
+// Serves two pages of releases for group/sub/tool, recording the token header of every request
+func newFakeGitlab(t *testing.T, tokens *[]string) *httptest.Server {
+	pages := map[string][]GitlabReleaseInfo{
+		"1": {
+			{TagName: "v2.0.0", UpcomingRelease: true},
+			{TagName: "v1.3.0-rc1"},
+		},
+		"2": {
+			{TagName: "v1.2.0"},
+			{TagName: "v1.1.0"},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*tokens = append(*tokens, r.Header.Get("PRIVATE-TOKEN"))
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Ftool/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		releases, exists := pages[r.URL.Query().Get("page")]
+		if !exists {
+			releases = []GitlabReleaseInfo{}
+		}
+		json.NewEncoder(w).Encode(releases)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Points utils.WebmanDir and utils.WebmanPkgDir at a temporary directory until the test ends
+func useTempWebmanDir(t *testing.T) {
+	webmanDir, pkgDir := utils.WebmanDir, utils.WebmanPkgDir
+	utils.WebmanDir = t.TempDir()
+	utils.WebmanPkgDir = filepath.Join(utils.WebmanDir, "pkg")
+	t.Cleanup(func() { utils.WebmanDir, utils.WebmanPkgDir = webmanDir, pkgDir })
+}
+
+func gitlabPkgConf(host string) *PkgConfig {
+	return &PkgConfig{
+		Title:          "tool",
+		LatestStrategy: "gitlab-release",
+		GitlabHost:     host + "/",
+		GitlabProject:  "group/sub/tool",
+		VersionFormat:  "v[VER]",
+	}
+}
+
+func TestGitlabReleaseConfigToken(t *testing.T) {
+	useTempWebmanDir(t)
+	t.Setenv("GITLAB_TOKEN", "env-token")
+	var tokens []string
+	srv := newFakeGitlab(t, &tokens)
+	u, _ := url.Parse(srv.URL)
+	config := "tokens:\n  " + u.Host + ": config-token\n"
+	if err := os.WriteFile(filepath.Join(utils.WebmanDir, "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ver, err := gitlabPkgConf(srv.URL).GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *ver != "1.2.0" {
+		t.Errorf("latest version = %q, want 1.2.0", *ver)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("made %d requests, want 2", len(tokens))
+	}
+	for _, token := range tokens {
+		if token != "config-token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want config-token", token)
+		}
+	}
+}
+
+func TestGitlabReleaseEnvToken(t *testing.T) {
+	useTempWebmanDir(t)
+	t.Setenv("GITLAB_TOKEN", "env-token")
+	var tokens []string
+	srv := newFakeGitlab(t, &tokens)
+
+	ver, err := gitlabPkgConf(srv.URL).GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *ver != "1.2.0" {
+		t.Errorf("latest version = %q, want 1.2.0", *ver)
+	}
+	if len(tokens) == 0 || tokens[0] != "env-token" {
+		t.Errorf("PRIVATE-TOKEN = %v, want env-token", tokens)
+	}
+}
+
+func TestGitlabReleasePrerelease(t *testing.T) {
+	useTempWebmanDir(t)
+	t.Setenv("GITLAB_TOKEN", "")
+	var tokens []string
+	srv := newFakeGitlab(t, &tokens)
+	pkgConf := gitlabPkgConf(srv.URL)
+	pkgConf.AllowPrerelease = true
+
+	ver, err := pkgConf.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *ver != "2.0.0" {
+		t.Errorf("latest version = %q, want 2.0.0", *ver)
+	}
+	if tokens[0] != "" {
+		t.Errorf("PRIVATE-TOKEN = %q, want none", tokens[0])
+	}
+}
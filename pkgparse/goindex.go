@@ -0,0 +1,90 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"webman/utils"
+)
+
+const goIndexUrl = "https://go.dev/dl/?mode=json&include=all"
+
+type goRelease struct {
+	Version string
+	Stable  bool
+	Files   []goFile
+}
+
+type goFile struct {
+	Filename string
+	Os       string
+	Arch     string
+	Sha256   string
+	Kind     string
+}
+
+type goIndex struct {
+	releases []goRelease
+}
+
+func getGoIndex() (*goIndex, error) {
+	body, err := fetchUrl(goIndexUrl)
+	if err != nil {
+		return nil, err
+	}
+	var idx goIndex
+	if err = json.Unmarshal(body, &idx.releases); err != nil {
+		return nil, fmt.Errorf("go.dev release index not in expected format")
+	}
+	return &idx, nil
+}
+
+func (idx *goIndex) versions() []VendorVersion {
+	vers := make([]VendorVersion, len(idx.releases))
+	for i, rel := range idx.releases {
+		vers[i] = VendorVersion{
+			Version: strings.TrimPrefix(rel.Version, "go"),
+			Stable:  rel.Stable,
+		}
+	}
+	return vers
+}
+
+// Finds the archive for the current platform in a Go release
+func (idx *goIndex) file(version string) (*goFile, error) {
+	goArch := utils.GOARCH
+	if goArch == "arm" {
+		goArch = "armv6l"
+	}
+	for _, rel := range idx.releases {
+		if rel.Version != "go"+version {
+			continue
+		}
+		for _, file := range rel.Files {
+			if file.Kind == "archive" && file.Os == utils.GOOS && file.Arch == goArch {
+				return &file, nil
+			}
+		}
+		return nil, fmt.Errorf("go %s has no archive for %s/%s", version, utils.GOOS, utils.GOARCH)
+	}
+	return nil, fmt.Errorf("go %s is not in the go.dev release index", version)
+}
+
+func (idx *goIndex) download(version string, ext string) (*VendorDownload, error) {
+	file, err := idx.file(version)
+	if err != nil {
+		return nil, err
+	}
+	return &VendorDownload{
+		Url:      "https://go.dev/dl/" + file.Filename,
+		FileName: file.Filename,
+	}, nil
+}
+
+func (idx *goIndex) checksum(version string, fileName string) (string, error) {
+	file, err := idx.file(version)
+	if err != nil || file.Filename != fileName {
+		return "", err
+	}
+	return file.Sha256, nil
+}
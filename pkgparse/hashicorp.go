@@ -0,0 +1,88 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"webman/utils"
+	"webman/vercmp"
+)
+
+const hashicorpReleasesUrl = "https://releases.hashicorp.com/"
+
+type hashicorpBuild struct {
+	Os       string
+	Arch     string
+	Filename string
+	Url      string
+}
+
+type hashicorpRelease struct {
+	Version string
+	Shasums string
+	Builds  []hashicorpBuild
+}
+
+type hashicorpIndex struct {
+	product  string
+	releases map[string]hashicorpRelease
+	// SHA256SUMS contents by version
+	sums map[string][]byte
+}
+
+func getHashicorpIndex(product string) (*hashicorpIndex, error) {
+	body, err := fetchUrl(hashicorpReleasesUrl + product + "/index.json")
+	if err != nil {
+		return nil, err
+	}
+	var index struct {
+		Versions map[string]hashicorpRelease
+	}
+	if err = json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("releases.hashicorp.com index for %s not in expected format", product)
+	}
+	return &hashicorpIndex{product: product, releases: index.Versions, sums: map[string][]byte{}}, nil
+}
+
+func (idx *hashicorpIndex) versions() []VendorVersion {
+	var vers []VendorVersion
+	for ver := range idx.releases {
+		// enterprise and other build variants like "1.7.0+ent" aren't separate versions
+		if strings.Contains(ver, "+") {
+			continue
+		}
+		vers = append(vers, VendorVersion{Version: ver, Stable: !vercmp.IsPrerelease(ver)})
+	}
+	return vers
+}
+
+func (idx *hashicorpIndex) download(version string, ext string) (*VendorDownload, error) {
+	rel, exists := idx.releases[version]
+	if !exists {
+		return nil, fmt.Errorf("%s %s is not in the releases.hashicorp.com index", idx.product, version)
+	}
+	for _, build := range rel.Builds {
+		if build.Os == utils.GOOS && build.Arch == utils.GOARCH {
+			return &VendorDownload{Url: build.Url, FileName: build.Filename}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s %s has no build for %s/%s", idx.product, version, utils.GOOS, utils.GOARCH)
+}
+
+// Looks the file up in the release's SHA256SUMS file
+func (idx *hashicorpIndex) checksum(version string, fileName string) (string, error) {
+	rel, exists := idx.releases[version]
+	if !exists || rel.Shasums == "" {
+		return "", nil
+	}
+	sums, exists := idx.sums[version]
+	if !exists {
+		var err error
+		sums, err = fetchUrl(hashicorpReleasesUrl + idx.product + "/" + version + "/" + rel.Shasums)
+		if err != nil {
+			return "", fmt.Errorf("unable to download %s checksums: %v", idx.product, err)
+		}
+		idx.sums[version] = sums
+	}
+	return findChecksum(sums, fileName)
+}
@@ -0,0 +1,54 @@
+package pkgparse
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Compiles versions_regex, which must capture the version string in its first group
+func compileVersionsRegex(versionsRegex string) (*regexp.Regexp, error) {
+	exp, err := regexp.Compile(versionsRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid versions_regex: %v", err)
+	}
+	if exp.NumSubexp() == 0 {
+		return nil, fmt.Errorf("versions_regex must have a capture group for the version")
+	}
+	return exp, nil
+}
+
+// Returns an error if versions_regex doesn't compile or has no capture group
+func CheckVersionsRegex(versionsRegex string) error {
+	_, err := compileVersionsRegex(versionsRegex)
+	return err
+}
+
+// Scrapes a download page or directory index for every string captured by versions_regex
+func listHtmlRegexMatches(url string, versionsRegex string) ([]string, error) {
+	exp, err := compileVersionsRegex(versionsRegex)
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetchUrl(url)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, match := range exp.FindAllStringSubmatch(string(body), -1) {
+		matches = append(matches, match[1])
+	}
+	return matches, nil
+}
+
+// Returns the scraped match with the highest version, since pages rarely list versions in order
+func getLatestHtmlRegexVersion(url string, versionsRegex string, versionFmt string, allowPrerelease bool) (*string, error) {
+	matches, err := listHtmlRegexMatches(url, versionsRegex)
+	if err != nil {
+		return nil, err
+	}
+	match, found := latestTag(matches, versionFmt, allowPrerelease)
+	if !found {
+		return nil, fmt.Errorf("found no versions matching versions_regex at %s", url)
+	}
+	return &match, nil
+}
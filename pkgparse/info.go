@@ -42,7 +42,12 @@ func ParseMultiPkgInfo(pkgs []string) ([]PkgInfo, error) {
 		i := i
 		pkg := pkg
 		eg.Go(func() error {
-			pkgInfo, err := ParsePkgInfo(pkg)
+			// group files may give a version, like "node@lts"
+			name, _, err := utils.ParsePkgVer(pkg)
+			if err != nil {
+				return nil
+			}
+			pkgInfo, err := ParsePkgInfo(name)
 			if err != nil {
 				return nil
 			}
@@ -0,0 +1,46 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"webman/jsonpath"
+)
+
+// Fetches a JSON document and returns every string or number the selector picks out
+func listJsonApiValues(url string, selector string) ([]string, error) {
+	path, err := jsonpath.Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetchUrl(url)
+	if err != nil {
+		return nil, err
+	}
+	values, err := path.EvaluateJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse JSON from %s: %v", url, err)
+	}
+	var strs []string
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			strs = append(strs, v)
+		case json.Number:
+			strs = append(strs, v.String())
+		}
+	}
+	return strs, nil
+}
+
+// Returns the selected value with the highest version
+func getLatestJsonApiVersion(url string, selector string, versionFmt string, allowPrerelease bool) (*string, error) {
+	values, err := listJsonApiValues(url, selector)
+	if err != nil {
+		return nil, err
+	}
+	value, found := latestTag(values, versionFmt, allowPrerelease)
+	if !found {
+		return nil, fmt.Errorf("found no versions selected by %s at %s", selector, url)
+	}
+	return &value, nil
+}
@@ -0,0 +1,117 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"webman/utils"
+)
+
+const nodeDistUrl = "https://nodejs.org/dist/"
+
+type nodeRelease struct {
+	Version string
+	Files   []string
+	// false, or the name of the LTS line like "Iron"
+	Lts interface{}
+}
+
+type nodeIndex struct {
+	releases []nodeRelease
+	// SHASUMS256.txt contents by version
+	sums map[string][]byte
+}
+
+var nodeOsMap = map[string]string{
+	"darwin":  "darwin",
+	"linux":   "linux",
+	"windows": "win",
+}
+
+var nodeArchMap = map[string]string{
+	"amd64":   "x64",
+	"arm64":   "arm64",
+	"386":     "x86",
+	"arm":     "armv7l",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+func getNodeIndex() (*nodeIndex, error) {
+	body, err := fetchUrl(nodeDistUrl + "index.json")
+	if err != nil {
+		return nil, err
+	}
+	idx := nodeIndex{sums: map[string][]byte{}}
+	if err = json.Unmarshal(body, &idx.releases); err != nil {
+		return nil, fmt.Errorf("nodejs.org release index not in expected format")
+	}
+	return &idx, nil
+}
+
+func (idx *nodeIndex) versions() []VendorVersion {
+	vers := make([]VendorVersion, len(idx.releases))
+	for i, rel := range idx.releases {
+		vers[i] = VendorVersion{
+			Version: strings.TrimPrefix(rel.Version, "v"),
+			Stable:  true,
+			Lts:     rel.Lts != nil && rel.Lts != false,
+		}
+	}
+	return vers
+}
+
+func (idx *nodeIndex) download(version string, ext string) (*VendorDownload, error) {
+	nodeOs, osExists := nodeOsMap[utils.GOOS]
+	nodeArch, archExists := nodeArchMap[utils.GOARCH]
+	if !osExists || !archExists {
+		return nil, fmt.Errorf("node has no builds for %s/%s", utils.GOOS, utils.GOARCH)
+	}
+	if ext == "" {
+		ext = "tar.gz"
+		if utils.GOOS == "windows" {
+			ext = "zip"
+		}
+	}
+	// the index names macOS builds "osx", unlike the file names
+	filesKey := nodeOs + "-" + nodeArch
+	if utils.GOOS == "darwin" {
+		filesKey = "osx-" + nodeArch
+	}
+	found := false
+	for _, rel := range idx.releases {
+		if rel.Version != "v"+version {
+			continue
+		}
+		for _, file := range rel.Files {
+			if strings.HasPrefix(file, filesKey) {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("node %s has no build for %s/%s", version, utils.GOOS, utils.GOARCH)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("node %s is not in the nodejs.org release index", version)
+	}
+	fileName := fmt.Sprintf("node-v%s-%s-%s.%s", version, nodeOs, nodeArch, ext)
+	return &VendorDownload{
+		Url:      nodeDistUrl + "v" + version + "/" + fileName,
+		FileName: fileName,
+	}, nil
+}
+
+// Looks the file up in the release's SHASUMS256.txt
+func (idx *nodeIndex) checksum(version string, fileName string) (string, error) {
+	sums, exists := idx.sums[version]
+	if !exists {
+		var err error
+		sums, err = fetchUrl(nodeDistUrl + "v" + version + "/SHASUMS256.txt")
+		if err != nil {
+			return "", fmt.Errorf("unable to download node checksums: %v", err)
+		}
+		idx.sums[version] = sums
+	}
+	return findChecksum(sums, fileName)
+}
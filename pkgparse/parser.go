@@ -1,6 +1,7 @@
 package pkgparse
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -23,6 +24,13 @@ type OsInfo struct {
 	BinPaths SingleOrMulti `yaml:"bin_path"`
 }
 
+// Completion scripts shipped by a package, relative to the version directory
+type CompletionInfo struct {
+	Bash string `yaml:"bash"`
+	Zsh  string `yaml:"zsh"`
+	Fish string `yaml:"fish"`
+}
+
 type OsArchPair struct {
 	Os   string `yaml:"os"`
 	Arch string `yaml:"arch"`
@@ -41,18 +49,58 @@ type PkgConfig struct {
 	SourceUrl       string `yaml:"source_url"`
 
 	FilenameFormat   string `yaml:"filename_format"`
+	AssetPattern     string `yaml:"asset_pattern"`
+	AssetRegex       string `yaml:"asset_regex"`
 	VersionFormat    string `yaml:"version_format"`
 	LatestStrategy   string `yaml:"latest_strategy"`
 	ForceLatest      bool   `yaml:"force_latest"`
 	AllowPrerelease  bool   `yaml:"allow_prerelease"`
 	ArchLinuxPkgName string `yaml:"arch_linux_pkg_name"`
+	ArchLinuxRepo    string `yaml:"arch_linux_repo"`
+	GitlabHost       string `yaml:"gitlab_host"`
+	GitlabProject    string `yaml:"gitlab_project"`
+	GiteaHost        string `yaml:"gitea_host"`
+	VersionsUrl      string `yaml:"versions_url"`
+	VersionsRegex    string `yaml:"versions_regex"`
+	VersionsSelector string `yaml:"versions_selector"`
+	LtsOnly          bool   `yaml:"lts_only"`
+	HashicorpProduct string `yaml:"hashicorp_product"`
+
+	Versions     []string `yaml:"versions"`
+	VersionsFile string   `yaml:"versions_file"`
+
+	Channels map[string]Channel `yaml:"channels"`
+
+	IsBinary        bool   `yaml:"is_binary"`
+	ExtractHasRoot  bool   `yaml:"extract_has_root"`
+	StripComponents int    `yaml:"strip_components"`
+	ExtractSubdir   string `yaml:"extract_subdir"`
+
+	ChecksumUrl string `yaml:"checksum_url"`
+	// digests keyed by version, then by platform like linux-amd64
+	Sha256       map[string]map[string]string `yaml:"sha256"`
+	GithubDigest bool                         `yaml:"github_digest"`
+
+	SignatureUrl       string `yaml:"signature_url"`
+	SignatureType      string `yaml:"signature_type"`
+	SignatureNamespace string `yaml:"signature_namespace"`
+	PublicKey          string `yaml:"public_key"`
 
-	IsBinary       bool `yaml:"is_binary"`
-	ExtractHasRoot bool `yaml:"extract_has_root"`
+	ExtraArtifacts []ArtifactConfig `yaml:"extra_artifacts"`
 
-	OsMap   map[string]OsInfo `yaml:"os_map"`
-	ArchMap map[string]string `yaml:"arch_map"`
-	Ignore  []OsArchPair      `yaml:"ignore"`
+	Completions CompletionInfo `yaml:"completions"`
+	ManPages    SingleOrMulti  `yaml:"man_pages"`
+
+	Env     map[string]string `yaml:"env"`
+	Depends []string          `yaml:"depends"`
+
+	OsMap     map[string]OsInfo       `yaml:"os_map"`
+	ArchMap   map[string]string       `yaml:"arch_map"`
+	Platforms map[string]PlatformInfo `yaml:"platforms"`
+	Ignore    []OsArchPair            `yaml:"ignore"`
+
+	vendorIdx vendorIndex
+	recipeDir string
 }
 
 var GOOStoPkgOs = map[string]string{
@@ -83,21 +131,17 @@ func (sm *SingleOrMulti) UnmarshalYAML(unmarshal func(interface{}) error) error
 }
 
 func (pkgConf *PkgConfig) GetMyBinPaths() ([]string, error) {
-	osStr, exists := GOOStoPkgOs[utils.GOOS]
-	if !exists {
-		return []string{}, fmt.Errorf("unsupported OS")
-	}
-	osInfo, exists := pkgConf.OsMap[osStr]
-	if !exists {
-		return []string{}, fmt.Errorf("package does not support this OS")
+	plat, err := pkgConf.GetMyPlatform()
+	if err != nil {
+		return []string{}, err
 	}
 	if pkgConf.IsBinary {
 		return []string{pkgConf.Title}, nil
 	}
-	if len(osInfo.BinPaths.Values) == 0 {
-		osInfo.BinPaths = SingleOrMulti{[]string{""}}
+	if len(plat.BinPaths.Values) == 0 {
+		return []string{""}, nil
 	}
-	return osInfo.BinPaths.Values, nil
+	return plat.BinPaths.Values, nil
 }
 
 // Check using file.
@@ -185,33 +229,116 @@ func ParsePkgConfigLocal(pkg string, strict bool) (*PkgConfig, error) {
 		}
 	}
 	pkgConf.Title = pkg
+	pkgConf.recipeDir = filepath.Dir(pkgConfPath)
 
-	pkgConf.BaseDownloadUrl = strings.ReplaceAll(pkgConf.BaseDownloadUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.BaseDownloadUrl = strings.ReplaceAll(pkgConf.BaseDownloadUrl, "[GIT_REPO]", pkgConf.GitRepo)
-
-	pkgConf.InfoUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.InfoUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_REPO]", pkgConf.GitRepo)
-
-	pkgConf.ReleasesUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.ReleasesUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_REPO]", pkgConf.GitRepo)
-
-	pkgConf.SourceUrl = strings.ReplaceAll(pkgConf.SourceUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.SourceUrl = strings.ReplaceAll(pkgConf.SourceUrl, "[GIT_REPO]", pkgConf.GitRepo)
+	gitReplacer := strings.NewReplacer(
+		"[GIT_HOST]", pkgConf.GetGitHost(),
+		"[GIT_USER]", pkgConf.GitUser,
+		"[GIT_REPO]", pkgConf.GitRepo,
+	)
+	for platform, plat := range pkgConf.Platforms {
+		plat.BaseDownloadUrl = gitReplacer.Replace(plat.BaseDownloadUrl)
+		pkgConf.Platforms[platform] = plat
+	}
+	for _, field := range []*string{
+		&pkgConf.BaseDownloadUrl,
+		&pkgConf.InfoUrl,
+		&pkgConf.ReleasesUrl,
+		&pkgConf.SourceUrl,
+		&pkgConf.ChecksumUrl,
+		&pkgConf.SignatureUrl,
+		&pkgConf.VersionsUrl,
+	} {
+		*field = gitReplacer.Replace(*field)
+	}
 
 	return &pkgConf, nil
 }
 
+// Returns the URL of the forge hosting the package's git repo, used for [GIT_HOST]
+func (pkgConf *PkgConfig) GetGitHost() string {
+	switch pkgConf.LatestStrategy {
+	case "gitlab-release":
+		return pkgConf.GetGitlabHost()
+	case "gitea-release":
+		return pkgConf.GetGiteaHost()
+	}
+	return "https://github.com"
+}
+
 func (pkgConf *PkgConfig) GetLatestVersion() (*string, error) {
 	var version string
 	switch pkgConf.LatestStrategy {
 	case "github-release":
 		rel, err := getLatestGithubReleaseTag(pkgConf.GitUser, pkgConf.GitRepo, pkgConf.AllowPrerelease)
+		var rateErr *GithubRateLimitError
+		if errors.As(err, &rateErr) && !pkgConf.AllowPrerelease {
+			// the redirect finds the same latest stable release without using the API
+			tag, redirectErr := getLatestGithubRedirectTag(pkgConf.GitUser, pkgConf.GitRepo)
+			if redirectErr != nil {
+				return nil, fmt.Errorf("%v, and the releases/latest fallback failed: %v", err, redirectErr)
+			}
+			version = *tag
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		version = rel.TagName
+	case "github-latest-redirect":
+		tag, err := getLatestGithubRedirectTag(pkgConf.GitUser, pkgConf.GitRepo)
+		if err != nil {
+			return nil, err
+		}
+		version = *tag
+	case "github-tags":
+		tag, err := getLatestGithubTag(pkgConf.GitUser, pkgConf.GitRepo, pkgConf.VersionFormat, pkgConf.AllowPrerelease)
+		if err != nil {
+			return nil, err
+		}
+		version = *tag
+	case "gitlab-release":
+		rel, err := getLatestGitlabReleaseTag(pkgConf.GetGitlabHost(), pkgConf.GetGitlabProject(),
+			pkgConf.VersionFormat, pkgConf.AllowPrerelease)
+		if err != nil {
+			return nil, err
+		}
+		version = rel.TagName
+	case "gitea-release":
+		rel, err := getLatestGiteaReleaseTag(pkgConf.GetGiteaHost(), pkgConf.GitUser, pkgConf.GitRepo,
+			pkgConf.AllowPrerelease)
 		if err != nil {
 			return nil, err
 		}
 		version = rel.TagName
-	case "arch-linux-community":
-		rel, err := getLatestArchLinuxPkgVersion(pkgConf.ArchLinuxPkgName)
+	case "html-regex":
+		match, err := getLatestHtmlRegexVersion(pkgConf.VersionsUrl, pkgConf.VersionsRegex,
+			pkgConf.VersionFormat, pkgConf.AllowPrerelease)
+		if err != nil {
+			return nil, err
+		}
+		version = *match
+	case "json-api":
+		value, err := getLatestJsonApiVersion(pkgConf.VersionsUrl, pkgConf.VersionsSelector,
+			pkgConf.VersionFormat, pkgConf.AllowPrerelease)
+		if err != nil {
+			return nil, err
+		}
+		version = *value
+	case "go-index", "node-index", "zig-index", "hashicorp-index":
+		ver, err := pkgConf.getLatestVendorVersion()
+		if err != nil {
+			return nil, err
+		}
+		version = *ver
+	case "static", "file":
+		ver, err := pkgConf.getLatestStaticVersion()
+		if err != nil {
+			return nil, err
+		}
+		version = *ver
+	case "arch-linux", "arch-linux-community":
+		rel, err := getLatestArchLinuxPkgVersion(pkgConf.ArchLinuxPkgName, pkgConf.ArchLinuxRepo)
 		if err != nil {
 			return nil, err
 		}
@@ -246,33 +373,37 @@ func ParseVersion(versionStr string, versionFmt string) (*string, error) {
 
 ///
 func (pkgConf *PkgConfig) GetAssetStemExtUrl(version string) (*string, *string, *string, error) {
-	pkgOs, exists := GOOStoPkgOs[utils.GOOS]
-	if !exists {
-		return nil, nil, nil, fmt.Errorf("unsupported operating system")
+	plat, err := pkgConf.GetMyPlatform()
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	osInf, exists := pkgConf.OsMap[pkgOs]
-	if !exists {
-		return nil, nil, nil, fmt.Errorf("package has no binary for operating system: %s", pkgOs)
+	if pkgConf.UsesAssetPattern() {
+		asset, err := pkgConf.findReleaseAsset(version, plat)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fileStem := asset.Name
+		if plat.Ext != "" {
+			fileStem = strings.TrimSuffix(fileStem, "."+plat.Ext)
+		}
+		return &fileStem, &plat.Ext, &asset.BrowserDownloadUrl, nil
+	}
+	if pkgConf.UsesVendorDownloads() {
+		return pkgConf.getVendorAssetStemExtUrl(version, plat)
 	}
-	archStr, exists := pkgConf.ArchMap[utils.GOARCH]
-	if !exists {
-		return nil, nil, nil, fmt.Errorf("package has no binary for architecture: %s", utils.GOARCH)
+	vars := pkgConf.templateVars(version, plat)
+	baseUrl, err := renderTemplate(plat.BaseDownloadUrl, vars)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fileStem, err := renderTemplate(strings.ReplaceAll(plat.FilenameFormat, ".[EXT]", ""), vars)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	baseUrl := pkgConf.BaseDownloadUrl
-	baseUrl = strings.ReplaceAll(baseUrl, "[VER]", version)
-	baseUrl = strings.ReplaceAll(baseUrl, "[OS]", osInf.Name)
-	baseUrl = strings.ReplaceAll(baseUrl, "[ARCH]", archStr)
-	baseUrl = strings.ReplaceAll(baseUrl, "[EXT]", osInf.Ext)
-
-	fileStem := pkgConf.FilenameFormat
-	fileStem = strings.ReplaceAll(fileStem, "[VER]", version)
-	fileStem = strings.ReplaceAll(fileStem, "[OS]", osInf.Name)
-	fileStem = strings.ReplaceAll(fileStem, "[ARCH]", archStr)
-	fileStem = strings.ReplaceAll(fileStem, ".[EXT]", "")
 	dot := ""
-	if osInf.Ext != "" {
+	if plat.Ext != "" {
 		dot = "."
 	}
-	stem := baseUrl + fileStem + dot + osInf.Ext
-	return &fileStem, &osInf.Ext, &stem, nil
+	stem := baseUrl + fileStem + dot + plat.Ext
+	return &fileStem, &plat.Ext, &stem, nil
 }
@@ -0,0 +1,103 @@
+package pkgparse
+
+import (
+	"fmt"
+	"webman/unpack"
+	"webman/utils"
+)
+
+// Recipe fields overridden for a single OS + arch pair, keyed like "linux-arm64".
+// Fields left unset fall back to the os_map entry and the top-level recipe fields.
+type PlatformInfo struct {
+	Ext             *string       `yaml:"ext"`
+	BinPaths        SingleOrMulti `yaml:"bin_path"`
+	FilenameFormat  string        `yaml:"filename_format"`
+	BaseDownloadUrl string        `yaml:"base_download_url"`
+	ExtractHasRoot  *bool         `yaml:"extract_has_root"`
+	StripComponents *int          `yaml:"strip_components"`
+	ExtractSubdir   *string       `yaml:"extract_subdir"`
+}
+
+// The recipe fields for one OS + arch pair, after applying any platforms override
+type Platform struct {
+	OsInfo
+	Arch            string
+	FilenameFormat  string
+	BaseDownloadUrl string
+	ExtractHasRoot  bool
+	StripComponents int
+	ExtractSubdir   string
+}
+
+// Returns the key used for the current platform in per-platform recipe maps, like "linux-amd64"
+func PlatformKey() string {
+	return GOOStoPkgOs[utils.GOOS] + "-" + utils.GOARCH
+}
+
+// Resolves the recipe fields for the current OS and architecture
+func (pkgConf *PkgConfig) GetMyPlatform() (*Platform, error) {
+	pkgOs, exists := GOOStoPkgOs[utils.GOOS]
+	if !exists {
+		return nil, fmt.Errorf("unsupported operating system")
+	}
+	osInf, exists := pkgConf.OsMap[pkgOs]
+	if !exists {
+		return nil, fmt.Errorf("package has no binary for operating system: %s", pkgOs)
+	}
+	archStr, exists := pkgConf.ArchMap[utils.GOARCH]
+	if !exists {
+		return nil, fmt.Errorf("package has no binary for architecture: %s", utils.GOARCH)
+	}
+	plat := Platform{
+		OsInfo:          osInf,
+		Arch:            archStr,
+		FilenameFormat:  pkgConf.FilenameFormat,
+		BaseDownloadUrl: pkgConf.BaseDownloadUrl,
+		ExtractHasRoot:  pkgConf.ExtractHasRoot,
+		StripComponents: pkgConf.StripComponents,
+		ExtractSubdir:   pkgConf.ExtractSubdir,
+	}
+	override, exists := pkgConf.Platforms[PlatformKey()]
+	if !exists {
+		return &plat, nil
+	}
+	if override.Ext != nil {
+		plat.Ext = *override.Ext
+	}
+	if len(override.BinPaths.Values) != 0 {
+		plat.BinPaths = override.BinPaths
+	}
+	if override.FilenameFormat != "" {
+		plat.FilenameFormat = override.FilenameFormat
+	}
+	if override.BaseDownloadUrl != "" {
+		plat.BaseDownloadUrl = override.BaseDownloadUrl
+	}
+	if override.ExtractHasRoot != nil {
+		plat.ExtractHasRoot = *override.ExtractHasRoot
+	}
+	if override.StripComponents != nil {
+		plat.StripComponents = *override.StripComponents
+	}
+	if override.ExtractSubdir != nil {
+		plat.ExtractSubdir = *override.ExtractSubdir
+	}
+	return &plat, nil
+}
+
+// Returns how the archive for the given version should be unpacked on the current platform
+func (pkgConf *PkgConfig) GetUnpackOptions(version string) (*unpack.Options, error) {
+	plat, err := pkgConf.GetMyPlatform()
+	if err != nil {
+		return nil, err
+	}
+	subdir, err := renderTemplate(plat.ExtractSubdir, pkgConf.templateVars(version, plat))
+	if err != nil {
+		return nil, err
+	}
+	return &unpack.Options{
+		HasRoot:         plat.ExtractHasRoot,
+		StripComponents: plat.StripComponents,
+		Subdir:          subdir,
+	}, nil
+}
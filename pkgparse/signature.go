@@ -0,0 +1,45 @@
+package pkgparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SignatureMinisign = "minisign"
+	SignatureSsh      = "ssh"
+)
+
+// Returns whether the recipe declares a detached signature for its downloads
+func (pkgConf *PkgConfig) HasSignature() bool {
+	return pkgConf.SignatureUrl != ""
+}
+
+// Returns the signature format, inferring it from the public key when unset
+func (pkgConf *PkgConfig) GetSignatureType() string {
+	if pkgConf.SignatureType != "" {
+		return pkgConf.SignatureType
+	}
+	key := strings.TrimSpace(pkgConf.PublicKey)
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-") {
+		return SignatureSsh
+	}
+	return SignatureMinisign
+}
+
+// Downloads the detached signature for the given version
+func (pkgConf *PkgConfig) GetSignature(version string) ([]byte, error) {
+	plat, err := pkgConf.GetMyPlatform()
+	if err != nil {
+		return nil, err
+	}
+	sigUrl, err := renderTemplate(pkgConf.SignatureUrl, pkgConf.templateVars(version, plat))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := fetchUrl(sigUrl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download signature: %v", err)
+	}
+	return sig, nil
+}
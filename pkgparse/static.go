@@ -0,0 +1,94 @@
+package pkgparse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Returns the path of versions_file, which may be a file:// URL
+// or a path relative to the directory holding the recipe
+func (pkgConf *PkgConfig) GetVersionsFilePath() (string, error) {
+	versionsFile := pkgConf.VersionsFile
+	if strings.HasPrefix(versionsFile, "file://") {
+		u, err := url.Parse(versionsFile)
+		if err != nil {
+			return "", fmt.Errorf("invalid versions_file: %v", err)
+		}
+		versionsFile = filepath.FromSlash(u.Path)
+	}
+	if filepath.IsAbs(versionsFile) {
+		return versionsFile, nil
+	}
+	if pkgConf.recipeDir == "" {
+		return "", fmt.Errorf("versions_file %q must be absolute for recipes not read from disk", pkgConf.VersionsFile)
+	}
+	return filepath.Join(pkgConf.recipeDir, versionsFile), nil
+}
+
+// Reads the versions listed in versions_file.
+// The file can be a YAML list, a YAML map with a versions list, or one version per line.
+func (pkgConf *PkgConfig) readVersionsFile() ([]string, error) {
+	versionsPath, err := pkgConf.GetVersionsFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(versionsPath)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	if err = yaml.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+	var manifest struct {
+		Versions []string `yaml:"versions"`
+	}
+	var mapping yaml.MapSlice
+	if err = yaml.Unmarshal(data, &mapping); err == nil && len(mapping) != 0 {
+		// a mapping is never a plain line list, so it has to be a manifest
+		if err = yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid versions file %s: %v", versionsPath, err)
+		}
+		if len(manifest.Versions) == 0 {
+			return nil, fmt.Errorf("versions file %s has no versions list", versionsPath)
+		}
+		return manifest.Versions, nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// Returns the versions listed by the static or file strategy
+func (pkgConf *PkgConfig) listStaticVersions() ([]string, error) {
+	if pkgConf.LatestStrategy == "file" {
+		return pkgConf.readVersionsFile()
+	}
+	return pkgConf.Versions, nil
+}
+
+// Returns the listed version with the highest version number
+func (pkgConf *PkgConfig) getLatestStaticVersion() (*string, error) {
+	vers, err := pkgConf.listStaticVersions()
+	if err != nil {
+		return nil, err
+	}
+	ver, found := latestTag(vers, pkgConf.VersionFormat, pkgConf.AllowPrerelease)
+	if !found {
+		return nil, fmt.Errorf("found no versions in the %s version list", pkgConf.LatestStrategy)
+	}
+	return &ver, nil
+}
@@ -0,0 +1,140 @@
+package pkgparse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Values available to recipe templates.
+// The bracket placeholders map onto these, e.g. [VER_MAJOR] is the same as {{.Major}}.
+type TemplateVars struct {
+	Version string
+	Tag     string
+	Major   string
+	Minor   string
+	Patch   string
+	Os      string
+	Arch    string
+	Ext     string
+	GitUser string
+	GitRepo string
+}
+
+var templateFuncs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// Returns the template values for a version on the given platform
+func (pkgConf *PkgConfig) templateVars(version string, plat *Platform) *TemplateVars {
+	core, _, _ := strings.Cut(version, "+")
+	core, _, _ = strings.Cut(core, "-")
+	parts := strings.SplitN(core, ".", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return &TemplateVars{
+		Version: version,
+		Tag:     pkgConf.TagForVersion(version),
+		Major:   parts[0],
+		Minor:   parts[1],
+		Patch:   parts[2],
+		Os:      plat.Name,
+		Arch:    plat.Arch,
+		Ext:     plat.Ext,
+		GitUser: pkgConf.GitUser,
+		GitRepo: pkgConf.GitRepo,
+	}
+}
+
+// Rebuilds the raw release tag for a version from version_format, e.g. "v[VER]" gives "v1.2.3".
+// This is only exact when version_format has no regex syntax besides anchors and escapes.
+func (pkgConf *PkgConfig) TagForVersion(version string) string {
+	versionFmt := pkgConf.VersionFormat
+	if versionFmt == "" {
+		return version
+	}
+	versionFmt = strings.TrimPrefix(versionFmt, "^")
+	versionFmt = strings.TrimSuffix(versionFmt, "$")
+	versionFmt = regexp.MustCompile(`\\(.)`).ReplaceAllString(versionFmt, "$1")
+	return strings.Replace(versionFmt, "[VER]", version, 1)
+}
+
+// Replaces the bracket placeholders, passing each value through quote
+func expandBrackets(s string, vars *TemplateVars, quote func(string) string) string {
+	return strings.NewReplacer(
+		"[VER]", quote(vars.Version),
+		"[TAG]", quote(vars.Tag),
+		"[VER_MAJOR]", quote(vars.Major),
+		"[VER_MINOR]", quote(vars.Minor),
+		"[VER_PATCH]", quote(vars.Patch),
+		"[OS]", quote(vars.Os),
+		"[ARCH]", quote(vars.Arch),
+		"[EXT]", quote(vars.Ext),
+	).Replace(s)
+}
+
+// Renders a recipe string, filling in bracket placeholders like [VER] and [OS]
+// and then executing any {{ }} actions as a Go text/template.
+func renderTemplate(s string, vars *TemplateVars) (string, error) {
+	return renderQuotedTemplate(s, vars, func(v string) string { return v })
+}
+
+// Like renderTemplate, but bracket placeholder values are passed through quote first.
+// Template actions are left as-is, so they can build any syntax the recipe needs.
+func renderQuotedTemplate(s string, vars *TemplateVars, quote func(string) string) (string, error) {
+	s = expandBrackets(s, vars, quote)
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("recipe").Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %v", s, err)
+	}
+	var buf strings.Builder
+	if err = tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("unable to render template %q: %v", s, err)
+	}
+	return buf.String(), nil
+}
+
+// Renders every templated recipe field with a sample version,
+// so that invalid templates are caught before anyone installs the package.
+func (pkgConf *PkgConfig) CheckTemplates() error {
+	for osStr, osInf := range pkgConf.OsMap {
+		plat := Platform{OsInfo: osInf, Arch: "arch", FilenameFormat: pkgConf.FilenameFormat,
+			BaseDownloadUrl: pkgConf.BaseDownloadUrl}
+		vars := pkgConf.templateVars("1.2.3", &plat)
+		fields := map[string]string{
+			"base_download_url": pkgConf.BaseDownloadUrl,
+			"filename_format":   pkgConf.FilenameFormat,
+			"checksum_url":      pkgConf.ChecksumUrl,
+			"signature_url":     pkgConf.SignatureUrl,
+			"asset_pattern":     pkgConf.AssetPattern,
+			"asset_regex":       pkgConf.AssetRegex,
+			"extract_subdir":    pkgConf.ExtractSubdir,
+		}
+		for i, artifact := range pkgConf.ExtraArtifacts {
+			fields[fmt.Sprintf("extra_artifacts[%d].url", i)] = artifact.Url
+			fields[fmt.Sprintf("extra_artifacts[%d].checksum_url", i)] = artifact.ChecksumUrl
+		}
+		for key, override := range pkgConf.Platforms {
+			fields["platforms."+key+".base_download_url"] = override.BaseDownloadUrl
+			fields["platforms."+key+".filename_format"] = override.FilenameFormat
+			if override.ExtractSubdir != nil {
+				fields["platforms."+key+".extract_subdir"] = *override.ExtractSubdir
+			}
+		}
+		for field, value := range fields {
+			if _, err := renderTemplate(value, vars); err != nil {
+				return fmt.Errorf("%s for %s: %v", field, osStr, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package pkgparse
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"webman/vercmp"
+)
+
+// A version listed in a vendor's release index
+type VendorVersion struct {
+	Version string
+	Stable  bool
+	Lts     bool
+}
+
+// A download for the current platform listed in a vendor's release index
+type VendorDownload struct {
+	Url      string
+	FileName string
+}
+
+// An official machine-readable release index, like go.dev/dl/?mode=json
+type vendorIndex interface {
+	versions() []VendorVersion
+	// Returns the download for the current platform, where ext is the recipe's archive extension
+	download(version string, ext string) (*VendorDownload, error)
+	// Returns the digest of the file, or an empty string if the index doesn't list it
+	checksum(version string, fileName string) (string, error)
+}
+
+// Returns whether the recipe resolves versions from a vendor release index
+func (pkgConf *PkgConfig) UsesVendorIndex() bool {
+	switch pkgConf.LatestStrategy {
+	case "go-index", "node-index", "zig-index", "hashicorp-index":
+		return true
+	}
+	return false
+}
+
+// Returns the HashiCorp product name, defaulting to the package name
+func (pkgConf *PkgConfig) GetHashicorpProduct() string {
+	if pkgConf.HashicorpProduct == "" {
+		return pkgConf.Title
+	}
+	return pkgConf.HashicorpProduct
+}
+
+// Returns whether downloads come straight from the vendor index,
+// rather than from a base_download_url set by the recipe
+func (pkgConf *PkgConfig) UsesVendorDownloads() bool {
+	return pkgConf.UsesVendorIndex() && pkgConf.BaseDownloadUrl == ""
+}
+
+// Fetches the vendor index once, reusing it for the version, download URL and checksum
+func (pkgConf *PkgConfig) getVendorIndex() (vendorIndex, error) {
+	if pkgConf.vendorIdx != nil {
+		return pkgConf.vendorIdx, nil
+	}
+	var idx vendorIndex
+	var err error
+	switch pkgConf.LatestStrategy {
+	case "go-index":
+		idx, err = getGoIndex()
+	case "node-index":
+		idx, err = getNodeIndex()
+	case "zig-index":
+		idx, err = getZigIndex()
+	case "hashicorp-index":
+		idx, err = getHashicorpIndex(pkgConf.GetHashicorpProduct())
+	default:
+		return nil, fmt.Errorf("%q is not a vendor index strategy", pkgConf.LatestStrategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkgConf.vendorIdx = idx
+	return idx, nil
+}
+
+// Returns the highest version in the vendor index, skipping unstable versions
+// unless allow_prerelease is set, and non-LTS versions if lts_only is set
+func (pkgConf *PkgConfig) getLatestVendorVersion() (*string, error) {
+	if pkgConf.LtsOnly && pkgConf.LatestStrategy != "node-index" {
+		return nil, fmt.Errorf("lts_only is only supported by the node-index latest strategy")
+	}
+	idx, err := pkgConf.getVendorIndex()
+	if err != nil {
+		return nil, err
+	}
+	var latest string
+	for _, ver := range idx.versions() {
+		if (!ver.Stable && !pkgConf.AllowPrerelease) || (!ver.Lts && pkgConf.LtsOnly) {
+			continue
+		}
+		if latest == "" || vercmp.Compare(ver.Version, latest) > 0 {
+			latest = ver.Version
+		}
+	}
+	if latest == "" {
+		return nil, fmt.Errorf("found no matching versions in the %s index", pkgConf.LatestStrategy)
+	}
+	return &latest, nil
+}
+
+// Returns the file stem, extension and URL of the vendor index download for the current platform
+func (pkgConf *PkgConfig) getVendorAssetStemExtUrl(version string, plat *Platform) (*string, *string, *string, error) {
+	idx, err := pkgConf.getVendorIndex()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dl, err := idx.download(version, plat.Ext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fileStem := dl.FileName
+	if plat.Ext != "" {
+		if !strings.HasSuffix(fileStem, "."+plat.Ext) {
+			return nil, nil, nil, fmt.Errorf("index download %s doesn't have the recipe's extension %q",
+				dl.FileName, plat.Ext)
+		}
+		fileStem = strings.TrimSuffix(fileStem, "."+plat.Ext)
+	}
+	return &fileStem, &plat.Ext, &dl.Url, nil
+}
+
+// Returns the digest the vendor index lists for the download at the given URL
+func (pkgConf *PkgConfig) getVendorChecksum(version string, url string) (string, error) {
+	idx, err := pkgConf.getVendorIndex()
+	if err != nil {
+		return "", err
+	}
+	sum, err := idx.checksum(version, path.Base(url))
+	if err != nil {
+		return "", err
+	}
+	if sum == "" {
+		return "", fmt.Errorf("the %s index has no checksum for %s", pkgConf.LatestStrategy, path.Base(url))
+	}
+	return sum, nil
+}
@@ -0,0 +1,134 @@
+package pkgparse
+
+import (
+	"fmt"
+	"sort"
+	"webman/vercmp"
+)
+
+// A version of a package that is available to install
+type RemoteVersion struct {
+	Version    string `json:"version"`
+	Prerelease bool   `json:"prerelease"`
+	// only set by indexes that mark long-term support releases
+	Lts bool `json:"lts,omitempty"`
+}
+
+// Picks the raw tag with the highest version from a list of tags,
+// skipping tags that don't match versionFmt and prereleases unless allowPrerelease is set
+func latestTag(tags []string, versionFmt string, allowPrerelease bool) (string, bool) {
+	var latest, latestVer string
+	for _, tag := range tags {
+		ver, err := ParseVersion(tag, versionFmt)
+		if err != nil || (!allowPrerelease && vercmp.IsPrerelease(*ver)) {
+			continue
+		}
+		if latest == "" || vercmp.Compare(*ver, latestVer) > 0 {
+			latest = tag
+			latestVer = *ver
+		}
+	}
+	return latest, latest != ""
+}
+
+// Lists every version the recipe's latest strategy can find, newest first.
+// Tags that don't match version_format are left out.
+func (pkgConf *PkgConfig) ListVersions() ([]RemoteVersion, error) {
+	var tags []RemoteVersion
+	switch pkgConf.LatestStrategy {
+	case "github-release", "github-latest-redirect":
+		releases, err := listGithubReleaseTags(pkgConf.GitUser, pkgConf.GitRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range releases {
+			if !rel.Draft {
+				tags = append(tags, RemoteVersion{Version: rel.TagName, Prerelease: rel.Prerelease})
+			}
+		}
+	case "github-tags":
+		names, err := listGithubTags(pkgConf.GitUser, pkgConf.GitRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			tags = append(tags, RemoteVersion{Version: name})
+		}
+	case "gitlab-release":
+		releases, err := listGitlabReleases(pkgConf.GetGitlabHost(), pkgConf.GetGitlabProject())
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range releases {
+			tags = append(tags, RemoteVersion{Version: rel.TagName, Prerelease: rel.UpcomingRelease})
+		}
+	case "gitea-release":
+		releases, err := listGiteaReleases(pkgConf.GetGiteaHost(), pkgConf.GitUser, pkgConf.GitRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range releases {
+			if !rel.Draft {
+				tags = append(tags, RemoteVersion{Version: rel.TagName, Prerelease: rel.Prerelease})
+			}
+		}
+	case "html-regex":
+		matches, err := listHtmlRegexMatches(pkgConf.VersionsUrl, pkgConf.VersionsRegex)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			tags = append(tags, RemoteVersion{Version: match})
+		}
+	case "json-api":
+		values, err := listJsonApiValues(pkgConf.VersionsUrl, pkgConf.VersionsSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			tags = append(tags, RemoteVersion{Version: value})
+		}
+	case "go-index", "node-index", "zig-index", "hashicorp-index":
+		idx, err := pkgConf.getVendorIndex()
+		if err != nil {
+			return nil, err
+		}
+		for _, ver := range idx.versions() {
+			tags = append(tags, RemoteVersion{Version: ver.Version, Prerelease: !ver.Stable, Lts: ver.Lts})
+		}
+	case "arch-linux", "arch-linux-community":
+		pkgs, err := listArchLinuxPkgs(pkgConf.ArchLinuxPkgName, pkgConf.ArchLinuxRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			tags = append(tags, RemoteVersion{Version: pkg.PkgVer})
+		}
+	case "static", "file":
+		listed, err := pkgConf.listStaticVersions()
+		if err != nil {
+			return nil, err
+		}
+		for _, ver := range listed {
+			tags = append(tags, RemoteVersion{Version: ver})
+		}
+	default:
+		return nil, fmt.Errorf("no implemented version listing for latest strategy %q", pkgConf.LatestStrategy)
+	}
+	seen := map[string]bool{}
+	var vers []RemoteVersion
+	for _, tag := range tags {
+		ver, err := ParseVersion(tag.Version, pkgConf.VersionFormat)
+		if err != nil || seen[*ver] {
+			continue
+		}
+		seen[*ver] = true
+		tag.Version = *ver
+		tag.Prerelease = tag.Prerelease || vercmp.IsPrerelease(*ver)
+		vers = append(vers, tag)
+	}
+	sort.SliceStable(vers, func(i, j int) bool {
+		return vercmp.Compare(vers[i].Version, vers[j].Version) > 0
+	})
+	return vers, nil
+}
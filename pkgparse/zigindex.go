@@ -0,0 +1,114 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"webman/utils"
+)
+
+const zigIndexUrl = "https://ziglang.org/download/index.json"
+
+type zigFile struct {
+	Tarball string
+	Shasum  string
+}
+
+type zigRelease struct {
+	Version string
+	Stable  bool
+	// files keyed like "x86_64-linux"
+	Files map[string]zigFile
+}
+
+type zigIndex struct {
+	releases []zigRelease
+}
+
+var zigOsMap = map[string]string{
+	"darwin":  "macos",
+	"linux":   "linux",
+	"windows": "windows",
+	"freebsd": "freebsd",
+}
+
+var zigArchMap = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"386":     "x86",
+	"arm":     "armv7a",
+	"riscv64": "riscv64",
+	"ppc64le": "powerpc64le",
+}
+
+func getZigIndex() (*zigIndex, error) {
+	body, err := fetchUrl(zigIndexUrl)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]map[string]json.RawMessage
+	if err = json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("ziglang.org release index not in expected format")
+	}
+	var idx zigIndex
+	for key, fields := range raw {
+		rel := zigRelease{Version: key, Stable: key != "master", Files: map[string]zigFile{}}
+		if key == "master" {
+			// the development build lists its real version separately
+			if err = json.Unmarshal(fields["version"], &rel.Version); err != nil {
+				continue
+			}
+		}
+		for field, value := range fields {
+			var file zigFile
+			if json.Unmarshal(value, &file) == nil && file.Tarball != "" {
+				rel.Files[field] = file
+			}
+		}
+		idx.releases = append(idx.releases, rel)
+	}
+	return &idx, nil
+}
+
+func (idx *zigIndex) versions() []VendorVersion {
+	vers := make([]VendorVersion, len(idx.releases))
+	for i, rel := range idx.releases {
+		vers[i] = VendorVersion{Version: rel.Version, Stable: rel.Stable}
+	}
+	return vers
+}
+
+// Finds the tarball for the current platform in a Zig release
+func (idx *zigIndex) file(version string) (*zigFile, error) {
+	key := zigArchMap[utils.GOARCH] + "-" + zigOsMap[utils.GOOS]
+	for _, rel := range idx.releases {
+		if rel.Version != version {
+			continue
+		}
+		file, exists := rel.Files[key]
+		if !exists {
+			return nil, fmt.Errorf("zig %s has no build for %s/%s", version, utils.GOOS, utils.GOARCH)
+		}
+		return &file, nil
+	}
+	return nil, fmt.Errorf("zig %s is not in the ziglang.org release index", version)
+}
+
+func (idx *zigIndex) download(version string, ext string) (*VendorDownload, error) {
+	file, err := idx.file(version)
+	if err != nil {
+		return nil, err
+	}
+	return &VendorDownload{
+		Url:      file.Tarball,
+		FileName: path.Base(file.Tarball),
+	}, nil
+}
+
+func (idx *zigIndex) checksum(version string, fileName string) (string, error) {
+	file, err := idx.file(version)
+	if err != nil || path.Base(file.Tarball) != fileName {
+		return "", err
+	}
+	return file.Shasum, nil
+}
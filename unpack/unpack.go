@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"webman/utils"
 )
 
@@ -28,9 +30,22 @@ var unpackMap = map[unpackExt]UnpackFn{
 	"exe.zip": Unzip,
 }
 
-func Unpack(src string, pkg string, stem string, ext string, hasRoot bool) error {
-	unpackFn, exists := unpackMap[unpackExt(ext)]
-	if !exists {
+// Options for which part of an archive ends up in the package directory
+type Options struct {
+	// Move the first top-level directory of the archive into place
+	HasRoot bool
+	// Drop this many leading path components from every entry, like tar's --strip-components
+	StripComponents int
+	// Only keep this directory inside the archive, given with forward slashes
+	Subdir string
+}
+
+func (opts *Options) isDirect() bool {
+	return !opts.HasRoot && opts.StripComponents == 0 && opts.Subdir == ""
+}
+
+func Unpack(src string, pkg string, stem string, ext string, opts Options) error {
+	if _, exists := unpackMap[unpackExt(ext)]; !exists {
 		return fmt.Errorf("no unpack function for extension: %q", ext)
 	}
 	pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
@@ -39,36 +54,135 @@ func Unpack(src string, pkg string, stem string, ext string, hasRoot bool) error
 		return fmt.Errorf("unable to create dir %q: %v", pkgDir, err)
 	}
 	pkgDest := filepath.Join(pkgDir, stem)
-	if hasRoot {
-		tmpPkgDir := filepath.Join(utils.WebmanTmpDir, pkg)
-		if err := os.MkdirAll(tmpPkgDir, 0755); err != nil {
-			return fmt.Errorf("unable to create dir %q: %v", tmpPkgDir, err)
+	// if this is a gzipped binary, there is no archive layout to select from
+	if ext == "gz" {
+		if err := os.MkdirAll(pkgDest, 0777); err != nil {
+			return fmt.Errorf("unable to create pkg destination dir %q: %v", pkgDest, err)
+		}
+		if err = UnGz(src, filepath.Join(pkgDest, pkg)); err != nil {
+			return fmt.Errorf("failed to extract file: %v", err)
+		}
+		return nil
+	}
+	return UnpackTo(src, pkgDest, ext, opts)
+}
+
+// Unpacks an archive into the dest directory, which may already exist
+func UnpackTo(src string, dest string, ext string, opts Options) error {
+	unpackFn, exists := unpackMap[unpackExt(ext)]
+	if !exists {
+		return fmt.Errorf("no unpack function for extension: %q", ext)
+	}
+	if opts.Subdir != "" {
+		// "." and "./" mean the archive root
+		opts.Subdir = path.Clean(opts.Subdir)
+		if opts.Subdir == "." {
+			opts.Subdir = ""
 		}
-		if err = unpackFn(src, tmpPkgDir); err != nil {
+	}
+	if opts.isDirect() {
+		if err := os.MkdirAll(dest, 0777); err != nil {
+			return fmt.Errorf("unable to create pkg destination dir %q: %v", dest, err)
+		}
+		if err := unpackFn(src, dest); err != nil {
 			return fmt.Errorf("failed to extract file: %v", err)
 		}
+		return nil
+	}
+	tmpPkgDir, err := os.MkdirTemp(utils.WebmanTmpDir, filepath.Base(dest)+"-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary dir: %v", err)
+	}
+	defer os.RemoveAll(tmpPkgDir)
+	if err = unpackFn(src, tmpPkgDir); err != nil {
+		return fmt.Errorf("failed to extract file: %v", err)
+	}
+	extractFolder := tmpPkgDir
+	if opts.HasRoot {
 		f, err := os.Open(tmpPkgDir)
 		if err != nil {
 			return fmt.Errorf("unable to open dir %q: %v", tmpPkgDir, err)
 		}
 		dir, err := f.ReadDir(1)
+		f.Close()
 		if err != nil {
 			return fmt.Errorf("unable to read dir %q: %v", tmpPkgDir, err)
 		}
-		extractFolder := filepath.Join(tmpPkgDir, dir[0].Name())
-		if err = os.Rename(extractFolder, pkgDest); err != nil {
-			return fmt.Errorf("unable to move %q to %q: %v", extractFolder, pkgDest, err)
+		extractFolder = filepath.Join(tmpPkgDir, dir[0].Name())
+	}
+	if opts.Subdir != "" {
+		extractFolder = filepath.Join(extractFolder, filepath.FromSlash(opts.Subdir))
+		if !strings.HasPrefix(extractFolder, filepath.Clean(tmpPkgDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid archive subdirectory %q", opts.Subdir)
 		}
-	} else {
-		if err := os.MkdirAll(pkgDest, 0777); err != nil {
-			return fmt.Errorf("unable to create pkg destination dir %q: %v", pkgDest, err)
+		if fi, err := os.Stat(extractFolder); err != nil || !fi.IsDir() {
+			return fmt.Errorf("archive has no directory %q", opts.Subdir)
 		}
-		// if this is a gzipped binary
-		if ext == "gz" {
-			pkgDest = filepath.Join(pkgDest, pkg)
+	}
+	if opts.StripComponents == 0 {
+		if err = mergeMove(extractFolder, dest); err != nil {
+			return fmt.Errorf("unable to move %q to %q: %v", extractFolder, dest, err)
 		}
-		if err = unpackFn(src, pkgDest); err != nil {
-			return fmt.Errorf("failed to extract file: %v", err)
+		return nil
+	}
+	if err = os.MkdirAll(dest, 0777); err != nil {
+		return fmt.Errorf("unable to create pkg destination dir %q: %v", dest, err)
+	}
+	if err = stripComponents(extractFolder, dest, opts.StripComponents); err != nil {
+		return fmt.Errorf("unable to strip %d path components: %v", opts.StripComponents, err)
+	}
+	return nil
+}
+
+// Moves everything below the first n levels of dir into dest.
+// Entries above that depth are dropped, and directories that end up
+// at the same path are merged, like tar does.
+func stripComponents(dir string, dest string, n int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		if n == 0 {
+			if err := mergeMove(entryPath, filepath.Join(dest, entry.Name())); err != nil {
+				return err
+			}
+		} else if entry.IsDir() {
+			if err := stripComponents(entryPath, dest, n-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Renames src to dst, merging directories when dst already exists
+func mergeMove(src string, dst string) error {
+	dstInfo, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !srcInfo.IsDir() || !dstInfo.IsDir() {
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		return os.Rename(src, dst)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := mergeMove(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -15,6 +15,7 @@ import (
 var WebmanDir string
 var WebmanPkgDir string
 var WebmanBinDir string
+var WebmanShareDir string
 var WebmanRecipeDir string
 var WebmanTmpDir string
 var RecipeDirFlag string
@@ -29,6 +30,7 @@ func Init() {
 	WebmanDir = filepath.Join(homeDir, "/.webman")
 	WebmanPkgDir = filepath.Join(WebmanDir, "/pkg")
 	WebmanBinDir = filepath.Join(WebmanDir, "/bin")
+	WebmanShareDir = filepath.Join(WebmanDir, "/share")
 	WebmanRecipeDir = filepath.Join(WebmanDir, "/recipes")
 	WebmanTmpDir = filepath.Join(WebmanDir, "/tmp")
 	GOOS = runtime.GOOS
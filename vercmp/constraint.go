@@ -0,0 +1,213 @@
+package vercmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type comparator struct {
+	op  string
+	ver string
+}
+
+// A version constraint, like "1.18", "^16", "~13.0", ">=0.9 <0.11" or "1.2 - 1.4 || ^2".
+// Space-separated comparisons must all hold, and "||" separates alternatives.
+// A full version like "1.2.3" only matches itself, while a partial version like "1.18"
+// or "1.18.x" matches any version starting with it.
+type Constraint struct {
+	spec   string
+	groups [][]comparator
+}
+
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+func ParseConstraint(spec string) (*Constraint, error) {
+	c := Constraint{spec: strings.TrimSpace(spec)}
+	if c.spec == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+	for _, alt := range strings.Split(c.spec, "||") {
+		group, err := parseGroup(alt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %v", spec, err)
+		}
+		c.groups = append(c.groups, group)
+	}
+	return &c, nil
+}
+
+func parseGroup(alt string) ([]comparator, error) {
+	fields := strings.Fields(alt)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty alternative")
+	}
+	// a hyphen range like "1.2 - 1.4"
+	if len(fields) == 3 && fields[1] == "-" {
+		lower, _ := partial(fields[0])
+		if lower == "" {
+			return nil, fmt.Errorf("invalid range start %q", fields[0])
+		}
+		group := []comparator{{op: ">=", ver: lower}}
+		upper, parts := partial(fields[2])
+		switch {
+		case upper == "":
+			return nil, fmt.Errorf("invalid range end %q", fields[2])
+		case parts != nil && len(parts) < 3:
+			group = append(group, comparator{op: "<", ver: bump(parts, len(parts)-1)})
+		default:
+			group = append(group, comparator{op: "<=", ver: upper})
+		}
+		return group, nil
+	}
+	var group []comparator
+	for _, field := range fields {
+		comps, err := parseField(field)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, comps...)
+	}
+	return group, nil
+}
+
+func parseField(field string) ([]comparator, error) {
+	switch field[0] {
+	case '^', '~':
+		ver, parts := partial(field[1:])
+		if parts == nil {
+			return nil, fmt.Errorf("%q needs a numeric version", field)
+		}
+		if len(parts) == 0 {
+			return nil, nil
+		}
+		bumpAt := len(parts) - 1
+		if field[0] == '~' && bumpAt > 1 {
+			bumpAt = 1
+		}
+		if field[0] == '^' {
+			// bump the first non-zero component, so ^1.2 allows 1.x and ^0.2 allows 0.2.x
+			for i, part := range parts {
+				if part != 0 {
+					bumpAt = i
+					break
+				}
+			}
+		}
+		return []comparator{{op: ">=", ver: ver}, {op: "<", ver: bump(parts, bumpAt)}}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(field, op) {
+			ver := strings.TrimPrefix(field, op)
+			if ver == "" {
+				return nil, fmt.Errorf("missing version after %q", op)
+			}
+			if op == "==" {
+				op = "="
+			}
+			return []comparator{{op: op, ver: ver}}, nil
+		}
+	}
+	ver, parts := partial(field)
+	if parts == nil || len(parts) >= 3 {
+		return []comparator{{op: "=", ver: field}}, nil
+	}
+	if len(parts) == 0 {
+		// "*" or "x" matches anything
+		return nil, nil
+	}
+	return []comparator{{op: ">=", ver: ver}, {op: "<", ver: bump(parts, len(parts)-1)}}, nil
+}
+
+// Splits a version into its numeric components, stopping at a wildcard like "x" or "*".
+// Returns nil parts if the version isn't purely numeric, like "1.2.3-rc1" or "2024-01-02".
+func partial(ver string) (string, []int) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(ver, "v"), "V")
+	parts := []int{}
+	var kept []string
+	for _, comp := range strings.Split(trimmed, ".") {
+		if comp == "x" || comp == "X" || comp == "*" {
+			break
+		}
+		num, err := strconv.Atoi(comp)
+		if err != nil || num < 0 {
+			return ver, nil
+		}
+		parts = append(parts, num)
+		kept = append(kept, comp)
+	}
+	return strings.Join(kept, "."), parts
+}
+
+// Returns the version after incrementing the component at index i and dropping the rest,
+// e.g. bumping index 1 of 1.18.3 gives 1.19
+func bump(parts []int, i int) string {
+	strs := make([]string, i+1)
+	for j := 0; j < i; j++ {
+		strs[j] = strconv.Itoa(parts[j])
+	}
+	strs[i] = strconv.Itoa(parts[i] + 1)
+	return strings.Join(strs, ".")
+}
+
+// Returns whether the version satisfies every comparison of any alternative of the constraint
+func (c *Constraint) Check(ver string) bool {
+	for _, group := range c.groups {
+		if checkGroup(group, ver) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkGroup(group []comparator, ver string) bool {
+	for _, comp := range group {
+		cmp := Compare(ver, comp.ver)
+		var ok bool
+		switch comp.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			// "<1.19" is an exclusive upper bound, so it also rules out 1.19's prereleases like 1.19rc1
+			ok = cmp < 0 && (IsPrerelease(comp.ver) || !IsPrerelease(ver) || Compare(release(ver), comp.ver) != 0)
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the version if the constraint only matches one exact version
+func (c *Constraint) Exact() (string, bool) {
+	if len(c.groups) == 1 && len(c.groups[0]) == 1 && c.groups[0][0].op == "=" {
+		return c.groups[0][0].ver, true
+	}
+	return "", false
+}
+
+// Returns whether the constraint names a prerelease version, like ">=2.0.0-rc1",
+// in which case prereleases may satisfy it
+func (c *Constraint) HasPrerelease() bool {
+	for _, group := range c.groups {
+		for _, comp := range group {
+			if IsPrerelease(comp.ver) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the constraint as it was written
+func (c *Constraint) String() string {
+	return c.spec
+}
@@ -0,0 +1,118 @@
+package vercmp
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		spec string
+		ver  string
+		want bool
+	}{
+		// partial versions
+		{"1.18", "1.18", true},
+		{"1.18", "1.18.3", true},
+		{"1.18", "1.18.3-rc1", true},
+		{"1.18", "1.19", false},
+		{"1.18", "1.19rc1", false},
+		{"1.18", "1.19.0-rc1", false},
+		{"1.18", "1.17.9", false},
+		{"1.18.x", "1.18.10", true},
+		{"*", "0.0.1", true},
+		// full versions only match themselves
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "v1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		// caret and tilde
+		{"^16", "16.20.1", true},
+		{"^16", "17.0.0", false},
+		{"^16", "17.0.0-rc1", false},
+		{"^16", "17.0.0-rc.1", false},
+		{"^0.2", "0.2.9", true},
+		{"^0.2", "0.3.0-beta.1", false},
+		{"~13.0", "13.0.5", true},
+		{"~13.0", "13.1.0", false},
+		{"~13.0", "13.1.0-rc1", false},
+		// operators
+		{">=0.9 <0.11", "0.10.1", true},
+		{">=0.9 <0.11", "0.11.0", false},
+		{">=0.9 <0.11", "0.11.0-dev.1", false},
+		{">=0.9 <0.11", "0.11.0-dev.1+abc", false},
+		{"<2.0.0-rc2", "2.0.0-rc1", true},
+		{"<2.0.0-rc2", "2.0.0-rc3", false},
+		{"<2", "1.99.99", true},
+		{"<2", "1.99.99-rc1", true},
+		{"!=1.2", "1.2.0", false},
+		{"==1.2", "1.2.0", true},
+		{">1.2", "1.2.1", true},
+		{"<=1.2", "1.2.0", true},
+		// hyphen ranges
+		{"1.2 - 1.4", "1.4.9", true},
+		{"1.2 - 1.4", "1.5.0", false},
+		{"1.2 - 1.4", "1.5.0-rc1", false},
+		{"1.2 - 1.4.1", "1.4.1", true},
+		{"1.2 - 1.4.1", "1.4.2", false},
+		// alternatives
+		{"1.2 - 1.4 || ^2", "2.3.0", true},
+		{"1.2 - 1.4 || ^2", "1.7.0", false},
+		{"1.2 - 1.4 || ^2", "3.0.0-rc1", false},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.spec)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := c.Check(tt.ver); got != tt.want {
+			t.Errorf("%q.Check(%q) = %v, want %v", tt.spec, tt.ver, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintExact(t *testing.T) {
+	tests := []struct {
+		spec  string
+		exact string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"=1.2", "1.2"},
+		{"1.2", ""},
+		{"^1.2.3", ""},
+		{"1.2.3 || 1.2.4", ""},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.spec, err)
+		}
+		exact, isExact := c.Exact()
+		if exact != tt.exact || isExact != (tt.exact != "") {
+			t.Errorf("%q.Exact() = %q, %v, want %q", tt.spec, exact, isExact, tt.exact)
+		}
+	}
+}
+
+func TestConstraintHasPrerelease(t *testing.T) {
+	tests := map[string]bool{
+		">=2.0.0-rc1": true,
+		"1.22rc1":     true,
+		"^2":          false,
+		"1.18":        false,
+	}
+	for spec, want := range tests {
+		c, err := ParseConstraint(spec)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", spec, err)
+		}
+		if got := c.HasPrerelease(); got != want {
+			t.Errorf("%q.HasPrerelease() = %v, want %v", spec, got, want)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, spec := range []string{"", "   ", "1.2 ||", ">=", "^abc", "~", "x - 1.2"} {
+		if _, err := ParseConstraint(spec); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package vercmp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Words that mark a version as a prerelease when they appear inside a component, like "1.22rc1"
+var prereleaseWords = map[string]bool{
+	"a":        true,
+	"alpha":    true,
+	"b":        true,
+	"beta":     true,
+	"rc":       true,
+	"pre":      true,
+	"preview":  true,
+	"dev":      true,
+	"snapshot": true,
+	"nightly":  true,
+	"canary":   true,
+}
+
+// Splits a version into its dot-separated release components, its prerelease part
+// and its package revision, dropping any "v" prefix and "+build" metadata.
+// Calendar versions like "2024-01-15" are split on the dashes instead.
+// A purely numeric suffix like the "-1" of "2.4.3-1" is a packaging revision,
+// as used by Arch Linux and Debian, so it makes the version newer rather than a prerelease.
+func split(ver string) ([]string, string, string) {
+	ver = strings.TrimSpace(ver)
+	ver = strings.TrimPrefix(strings.TrimPrefix(ver, "v"), "V")
+	ver, _, _ = strings.Cut(ver, "+")
+	core, pre, _ := strings.Cut(ver, "-")
+	if pre != "" && !strings.Contains(core, ".") && isNumeric(core) && pre[0] >= '0' && pre[0] <= '9' {
+		core += "." + strings.ReplaceAll(pre, "-", ".")
+		pre = ""
+	}
+	if isNumeric(pre) {
+		return strings.Split(core, "."), "", pre
+	}
+	return strings.Split(core, "."), pre, ""
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Splits a component into runs of digits and runs of other characters, e.g. "22rc1" into 22, rc, 1
+func tokens(comp string) []string {
+	var toks []string
+	start := 0
+	for i := 1; i <= len(comp); i++ {
+		if i == len(comp) || isDigit(comp[i]) != isDigit(comp[start]) {
+			toks = append(toks, comp[start:i])
+			start = i
+		}
+	}
+	return toks
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Returns whether the token at index i is a prerelease word.
+// A lone "a" or "b" only counts when a number follows, as in "1.0a1", since "1.1.1a" is a patch release.
+func isPrereleaseToken(toks []string, i int) bool {
+	word := strings.ToLower(toks[i])
+	if len(word) == 1 && i+1 >= len(toks) {
+		return false
+	}
+	return prereleaseWords[word]
+}
+
+// Compares two tokens, numerically when both are numbers
+func compareToken(a string, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		a = strings.TrimLeft(a, "0")
+		b = strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		// numeric tokens sort before alphanumeric ones
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// Compares two release components token by token.
+// When one runs out of tokens, a trailing prerelease word like "rc" makes the other newer,
+// while any other trailing token, like the "w" of "1.1.1w", makes it older.
+func compareComponent(a string, b string) int {
+	aToks, bToks := tokens(a), tokens(b)
+	for i := 0; i < len(aToks) || i < len(bToks); i++ {
+		switch {
+		case i >= len(aToks):
+			if isPrereleaseToken(bToks, i) {
+				return 1
+			}
+			return -1
+		case i >= len(bToks):
+			if isPrereleaseToken(aToks, i) {
+				return -1
+			}
+			return 1
+		}
+		if c := compareToken(aToks[i], bToks[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compares two versions, returning -1 if a is older than b, 1 if it's newer, and 0 if they are equal.
+// Missing trailing components count as 0, so "1.2" equals "1.2.0",
+// prereleases like "1.2.0-rc1" or "1.2rc1" are older than "1.2.0",
+// and revisions like "1.2.0-1" are newer than it.
+func Compare(a string, b string) int {
+	aCore, aPre, aRev := split(a)
+	bCore, bPre, bRev := split(b)
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aCore) {
+			aPart = aCore[i]
+		}
+		if i < len(bCore) {
+			bPart = bCore[i]
+		}
+		if c := compareComponent(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case aPre == bPre:
+		// a missing revision counts as 0, so "2.4.3-1" is newer than "2.4.3"
+		return compareToken("0"+aRev, "0"+bRev)
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	aIds := strings.Split(aPre, ".")
+	bIds := strings.Split(bPre, ".")
+	for i := 0; i < len(aIds) && i < len(bIds); i++ {
+		if c := compareComponent(aIds[i], bIds[i]); c != 0 {
+			return c
+		}
+	}
+	return compareToken(strconv.Itoa(len(aIds)), strconv.Itoa(len(bIds)))
+}
+
+// Returns whether the version is a prerelease, like "1.0.0-rc1" or "1.22beta2"
+func IsPrerelease(ver string) bool {
+	core, pre, _ := split(ver)
+	if pre != "" {
+		return true
+	}
+	for _, comp := range core {
+		toks := tokens(comp)
+		for i := range toks {
+			if isPrereleaseToken(toks, i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the release a prerelease leads up to, like "1.19" for "1.19rc1" or "2.0.0" for "2.0.0-rc.1"
+func release(ver string) string {
+	core, _, _ := split(ver)
+	for i, comp := range core {
+		toks := tokens(comp)
+		for j := range toks {
+			if isPrereleaseToken(toks, j) {
+				core[i] = strings.Join(toks[:j], "")
+				break
+			}
+		}
+	}
+	return strings.Join(core, ".")
+}
+
+// Sorts versions from newest to oldest
+func SortDesc(vers []string) {
+	sort.SliceStable(vers, func(i, j int) bool {
+		return Compare(vers[i], vers[j]) > 0
+	})
+}
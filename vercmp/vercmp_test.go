@@ -0,0 +1,91 @@
+package vercmp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		// mixed lengths and prefixes
+		{"1.2", "1.2.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3+build.5", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.2.0.1", "1.2", 1},
+		{"10", "9.9.9", 1},
+		// Go-style prereleases
+		{"1.19rc1", "1.19", -1},
+		{"1.19rc1", "1.19rc2", -1},
+		{"1.19beta1", "1.19rc1", -1},
+		{"1.19rc1", "1.18.10", 1},
+		{"1.21.0", "1.21rc2", 1},
+		// semver prereleases
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0-rc.2", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"0.11.0-dev.1", "0.11.0", -1},
+		{"0.11.0-dev.1", "0.10.1", 1},
+		// numeric suffixes are packaging revisions
+		{"2.4.3-1", "2.4.3", 1},
+		{"2.4.3-2", "2.4.3-1", 1},
+		{"2.4.3-10", "2.4.3-9", 1},
+		{"2.4.3-1", "2.4.4", -1},
+		{"2.4.3-1", "2.4.3-rc1", 1},
+		{"13.0.0", "13.0.0-1", -1},
+		// letter patch releases and calendar versions
+		{"1.1.1w", "1.1.1", 1},
+		{"1.1.1w", "1.1.1v", 1},
+		{"1.0a1", "1.0", -1},
+		{"2024-01-15", "2023-12-31", 1},
+		{"2024-01-15", "2024-01-15", 0},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Compare(tt.b, tt.a); got != -tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	tests := map[string]bool{
+		"1.19rc1":      true,
+		"1.22beta2":    true,
+		"1.0a1":        true,
+		"1.0.0-rc.1":   true,
+		"0.11.0-dev.1": true,
+		"2.0.0-alpha":  true,
+		"1.19":         false,
+		"1.2.3":        false,
+		"1.1.1w":       false,
+		"1.1.1a":       false,
+		"2.4.3-1":      false,
+		"13.0.0-12":    false,
+		"2024-01-15":   false,
+		"v1.2.3+build": false,
+	}
+	for ver, want := range tests {
+		if got := IsPrerelease(ver); got != want {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", ver, got, want)
+		}
+	}
+}
+
+func TestSortDesc(t *testing.T) {
+	vers := []string{"1.18.2", "1.19rc1", "2.4.3-1", "1.19", "1.9", "2.4.3", "1.19.0-rc.2", "1.10.1", "2.4.3-rc1"}
+	SortDesc(vers)
+	want := []string{"2.4.3-1", "2.4.3", "2.4.3-rc1", "1.19", "1.19.0-rc.2", "1.19rc1", "1.18.2", "1.10.1", "1.9"}
+	if !reflect.DeepEqual(vers, want) {
+		t.Errorf("SortDesc = %v, want %v", vers, want)
+	}
+}
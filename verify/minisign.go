@@ -0,0 +1,103 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	minisignAlgLegacy   = "Ed"
+	minisignAlgHashed   = "ED"
+	minisignKeyIdLen    = 8
+	minisignTrustedNote = "trusted comment: "
+)
+
+// Returns the last line that isn't empty or an untrusted comment.
+// This lets recipes give either a bare base64 key or the full contents of a .pub file.
+func minisignPayload(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			return line
+		}
+	}
+	return ""
+}
+
+func parseMinisignPublicKey(publicKey string) ([]byte, ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(minisignPayload(publicKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid minisign public key: %v", err)
+	}
+	if len(raw) != 2+minisignKeyIdLen+ed25519.PublicKeySize || string(raw[:2]) != minisignAlgLegacy {
+		return nil, nil, fmt.Errorf("invalid minisign public key")
+	}
+	return raw[2 : 2+minisignKeyIdLen], ed25519.PublicKey(raw[2+minisignKeyIdLen:]), nil
+}
+
+// Verifies a minisign signature of the file at src against the given public key.
+// Both prehashed (default since minisign 0.10) and legacy signatures are supported.
+func Minisign(src string, sig []byte, publicKey string) error {
+	keyId, pub, err := parseMinisignPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(sig), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], minisignTrustedNote) {
+		return fmt.Errorf("invalid minisign signature file")
+	}
+	sigBlob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sigBlob) != 2+minisignKeyIdLen+ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	alg := string(sigBlob[:2])
+	if !bytes.Equal(sigBlob[2:2+minisignKeyIdLen], keyId) {
+		return fmt.Errorf("minisign signature was not made by the trusted key")
+	}
+	signature := sigBlob[2+minisignKeyIdLen:]
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var message []byte
+	switch alg {
+	case minisignAlgHashed:
+		h, err := blake2b.New512(nil)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	case minisignAlgLegacy:
+		if message, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", alg)
+	}
+	if !ed25519.Verify(pub, message, signature) {
+		return fmt.Errorf("minisign signature does not match file")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], minisignTrustedNote)
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign global signature")
+	}
+	if !ed25519.Verify(pub, append(signature, trustedComment...), globalSig) {
+		return fmt.Errorf("minisign trusted comment signature is invalid")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const sshSigMagic = "SSHSIG"
+
+// Wire format of an armored signature made by "ssh-keygen -Y sign"
+type sshSigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// Data that is actually signed by "ssh-keygen -Y sign", after the magic preamble
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// Verifies an SSH signature of the file at src, like "ssh-keygen -Y verify" would.
+// The public key is given in authorized_keys format,
+// and the namespace defaults to "file" when empty.
+func SshSignature(src string, sig []byte, publicKey string, namespace string) error {
+	if namespace == "" {
+		namespace = "file"
+	}
+	trusted, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return fmt.Errorf("invalid ssh public key: %v", err)
+	}
+	block, _ := pem.Decode(sig)
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return fmt.Errorf("invalid ssh signature file")
+	}
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSigMagic)) {
+		return fmt.Errorf("invalid ssh signature preamble")
+	}
+	var blob sshSigBlob
+	if err = ssh.Unmarshal(block.Bytes[len(sshSigMagic):], &blob); err != nil {
+		return fmt.Errorf("invalid ssh signature: %v", err)
+	}
+	if blob.Version != 1 {
+		return fmt.Errorf("unsupported ssh signature version %d", blob.Version)
+	}
+	if blob.Namespace != namespace {
+		return fmt.Errorf("ssh signature namespace %q does not match %q", blob.Namespace, namespace)
+	}
+	signer, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid ssh signature public key: %v", err)
+	}
+	if !bytes.Equal(signer.Marshal(), trusted.Marshal()) {
+		return fmt.Errorf("ssh signature was not made by the trusted key")
+	}
+	var signature ssh.Signature
+	if err = ssh.Unmarshal(blob.Signature, &signature); err != nil {
+		return fmt.Errorf("invalid ssh signature: %v", err)
+	}
+
+	var h hash.Hash
+	switch blob.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm %q", blob.HashAlgorithm)
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = io.Copy(h, f); err != nil {
+		return err
+	}
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     blob.Namespace,
+		Reserved:      blob.Reserved,
+		HashAlgorithm: blob.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+	if err = signer.Verify(signed, &signature); err != nil {
+		return fmt.Errorf("ssh signature does not match file: %v", err)
+	}
+	return nil
+}